@@ -2,41 +2,315 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// fbptcat prints the records of the Firmware Basic Boot Performance Table.
+//
+// Synopsis:
+//
+//	fbptcat [-v] [-strict] [-trace|-edk2|-ndjson|-sqlite db [-boot-id id]|-summary|-analyze|-chart|-html file|-check|-dump|-watch [-interval d]] [-scan-range start:length]
+//
+// Options:
+//
+//	-v:        log skipped and unknown records
+//	-strict:   fail if the FBPT is not within an ACPI or Reserved region of /proc/iomem
+//	-trace:    print the boot phases in Chrome trace event format
+//	-edk2:     print the boot phases like the EDK2 Dp shell command
+//	-ndjson:   print the records as newline delimited JSON
+//	-sqlite:   append the records to the given SQLite database, creating it
+//	           if absent, so the records of many boots accumulate in one file
+//	-boot-id:  the boot ID -sqlite stores the records under (default: the
+//	           kernel's boot ID)
+//	-summary:  print the boot phases, longest first, with their share of the
+//	           boot time
+//	-analyze:  print the firmware and loader time and the boot phases like
+//	           systemd-analyze
+//	-chart:    print the boot phases as a gantt-like chart
+//	-html:     write a self-contained HTML report with a gantt chart of the
+//	           boot phases to the given file
+//	-check:    report records whose timestamp is less than their
+//	           predecessor's and exit with status 1 if there are any
+//	-dump:     print a hexdump of the raw table bytes
+//	-scan-range: scan the given physical memory range for the FBPT instead
+//	           of using the pointer in the FPDT, for firmware without FPDT
+//	-watch:    after printing the records, re-read the table every interval
+//	           and print the records that appeared since the previous read
+//	-interval: how often -watch re-reads the table (default 2s)
 package main
 
 import (
+	"encoding/hex"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/u-root/u-root/pkg/acpi"
 	"github.com/u-root/u-root/pkg/acpi/fbpt"
 	"github.com/u-root/u-root/pkg/acpi/fpdt"
 )
 
+var (
+	verbose   = flag.Bool("v", false, "log skipped and unknown records")
+	strict    = flag.Bool("strict", false, "fail if the FBPT is not within an ACPI or Reserved region of /proc/iomem")
+	trace     = flag.Bool("trace", false, "print the boot phases in Chrome trace event format")
+	edk2      = flag.Bool("edk2", false, "print the boot phases like the EDK2 Dp shell command")
+	ndjson    = flag.Bool("ndjson", false, "print the records as newline delimited JSON")
+	sqlite    = flag.String("sqlite", "", "append the records to the given SQLite database")
+	bootID    = flag.String("boot-id", "", "the boot ID -sqlite stores the records under (default: the kernel's boot ID)")
+	chart     = flag.Bool("chart", false, "print the boot phases as a gantt-like chart")
+	html      = flag.String("html", "", "write an HTML report with a gantt chart of the boot phases to the given file")
+	check     = flag.Bool("check", false, "report records with non-monotonic timestamps")
+	dump      = flag.Bool("dump", false, "print a hexdump of the raw table bytes")
+	summary   = flag.Bool("summary", false, "print the boot phases, longest first, with their share of the boot time")
+	analyze   = flag.Bool("analyze", false, "print the firmware and loader time and the boot phases like systemd-analyze")
+	scanRange = flag.String("scan-range", "", "scan the start:length physical memory range for the FBPT instead of using the FPDT pointer")
+	watch     = flag.Bool("watch", false, "re-read the table periodically and print new records")
+	interval  = flag.Duration("interval", 2*time.Second, "how often -watch re-reads the table")
+)
+
+// chartWidth is the number of columns of the -chart bars.
+const chartWidth = 72
+
+// bootIDPath holds the kernel's random ID of the current boot.
+const bootIDPath = "/proc/sys/kernel/random/boot_id"
+
+// parseRange parses a start:length pair, both may be given in hex.
+func parseRange(s string) (uint64, uint64, error) {
+	startStr, lengthStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("range %q is not of the form start:length", s)
+	}
+	start, err := strconv.ParseUint(startStr, 0, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	length, err := strconv.ParseUint(lengthStr, 0, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, length, nil
+}
+
+// findFBPT returns the FBPT address, either from the FPDT or by scanning memory.
+func findFBPT() (uint64, error) {
+	if *scanRange != "" {
+		start, length, err := parseRange(*scanRange)
+		if err != nil {
+			return 0, err
+		}
+		f, err := os.Open("/dev/mem")
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		return fbpt.LocateFBPT(f, start, length)
+	}
+
+	// Get the FBPT pointer from the ACPI FPDT, fall back to the EFI variable
+	// if there is no FPDT or its pointer record is rejected
+	addr, err := fbptAddrFromFPDT()
+	if err != nil {
+		if efiAddr, ok := fbpt.FBPTAddrFromEFIVars(); ok {
+			log.Printf("FPDT unavailable (%v), using FBPT address %#x from EFI variable", err, efiAddr)
+			return efiAddr, nil
+		}
+		return 0, err
+	}
+	return addr, nil
+}
+
+// fbptAddrFromFPDT returns the FBPT pointer of the ACPI FPDT.
+func fbptAddrFromFPDT() (uint64, error) {
+	acpiFPDT, err := fpdt.ReadACPIFPDTTable()
+	if err != nil {
+		return 0, err
+	}
+	return fpdt.FindFBPTTableAdrr(acpiFPDT)
+}
+
 func main() {
-	// Get FPDT table from ACPI
-	var acpiFPDT acpi.Table = nil
-	var err error
-	if acpiFPDT, err = fpdt.ReadACPIFPDTTable(); err != nil {
-		fmt.Println(err)
+	flag.Parse()
+	if *watch && (*trace || *edk2 || *ndjson || *sqlite != "" || *summary || *analyze || *chart || *html != "" || *check || *dump) {
+		log.Fatal("-watch can't be combined with -trace, -edk2, -ndjson, -sqlite, -summary, -analyze, -chart, -html, -check or -dump")
 	}
 
-	// Get FBPT Pointer from FPDT Table
-	var FBPTAddr uint64
-	if FBPTAddr, err = fpdt.FindFBPTTableAdrr(acpiFPDT); err != nil {
+	FBPTAddr, err := findFBPT()
+	if err != nil {
 		log.Fatal(err)
 	}
 
-	var measurementRecords []fbpt.MEASUREMENT_RECORD
-	if _, measurementRecords, err = fbpt.FindAllFBPTRecords(FBPTAddr); err != nil {
+	if *dump {
+		table, err := fbpt.DumpTable(FBPTAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(hex.Dump(table))
+		return
+	}
+
+	scanner := fbpt.Scanner{Strict: *strict}
+	if *verbose {
+		scanner.Logger = log.Default()
+	}
+	measurementRecords, warnings, err := scanner.FindAllFBPTRecords(FBPTAddr)
+	if err != nil {
 		log.Fatal(err)
 	}
+	printWarnings(warnings)
+
+	if *summary {
+		if err := fbpt.WriteSummary(os.Stdout, measurementRecords, bootDuration(FBPTAddr, measurementRecords)); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *analyze {
+		// Without a usable basic boot record the records alone are analyzed.
+		boot, _ := fbpt.ReadBasicBootRecord(FBPTAddr)
+		if err := fbpt.WriteAnalyze(os.Stdout, boot, measurementRecords); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *html != "" {
+		if err := writeHTML(*html, measurementRecords); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *check {
+		if !checkRecords(measurementRecords) {
+			os.Exit(1)
+		}
+		return
+	}
 
+	if err := printRecords(measurementRecords); err != nil {
+		log.Fatal(err)
+	}
+	if *watch {
+		if err := watchRecords(&scanner, FBPTAddr, measurementRecords); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func printWarnings(warnings []error) {
+	if *verbose {
+		// A verbose scanner already logged them.
+		return
+	}
+	for _, warning := range warnings {
+		log.Printf("Warning: %v", warning)
+	}
+}
+
+// checkRecords reports the records that break the monotonic timestamp
+// sequence and returns whether there were none.
+func checkRecords(measurementRecords []fbpt.MEASUREMENT_RECORD) bool {
+	rollovers := fbpt.DetectRollover(measurementRecords)
+	for _, i := range rollovers {
+		fmt.Printf("Index: %d, Timestamp: %d is less than the previous timestamp %d\n", i, measurementRecords[i].Timestamp, measurementRecords[i-1].Timestamp)
+	}
+	if len(rollovers) == 0 {
+		fmt.Println("Timestamps are monotonic")
+	}
+	return len(rollovers) == 0
+}
+
+// writeHTML writes the HTML report of the records to path.
+func writeHTML(path string, measurementRecords []fbpt.MEASUREMENT_RECORD) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := fbpt.WriteHTML(f, measurementRecords); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// bootDuration returns the boot time from the basic boot record, or the time
+// spanned by the records if there is no usable basic boot record or its clock
+// does not match the one of the records.
+func bootDuration(FBPTAddr uint64, measurementRecords []fbpt.MEASUREMENT_RECORD) time.Duration {
+	if boot, err := fbpt.ReadBasicBootRecord(FBPTAddr); err != nil || boot.BootDuration() == 0 {
+		log.Printf("No usable basic boot record, using the time spanned by the records as boot time")
+	} else if fbpt.DetectClockMismatch(boot, measurementRecords) {
+		log.Printf("Warning: the basic boot record and the records use different clocks, using the time spanned by the records as boot time")
+	} else {
+		return boot.BootDuration()
+	}
+	var first, last uint64
 	for i, measurementRecord := range measurementRecords {
-		if measurementRecord.Timestamp == 0 && len(measurementRecord.HookType) == 0 && len(measurementRecord.Description) == 0 {
-			continue
+		if i == 0 || measurementRecord.Timestamp < first {
+			first = measurementRecord.Timestamp
+		}
+		if measurementRecord.Timestamp > last {
+			last = measurementRecord.Timestamp
+		}
+	}
+	return time.Duration(last - first)
+}
+
+// watchRecords re-reads the FBPT every interval and prints the records that
+// were not there in the previous read.
+func watchRecords(scanner *fbpt.Scanner, FBPTAddr uint64, previous []fbpt.MEASUREMENT_RECORD) error {
+	f, err := os.Open("/dev/mem")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for {
+		time.Sleep(*interval)
+		measurementRecords, warnings, err := scanner.ReadFBPTRecords(f, FBPTAddr)
+		if err != nil {
+			return err
+		}
+		printWarnings(warnings)
+		for i, measurementRecord := range fbpt.NewRecords(previous, measurementRecords) {
+			printRecord(len(previous)+i, measurementRecord)
 		}
-		fmt.Printf("Index: %d,Hook Type: %s, Processor Identifier/APIC ID: %d, Timestamp: %d, Guid: %s, Description: %s\n", i, measurementRecord.HookType, measurementRecord.ProcessorIdentifier, measurementRecord.Timestamp, measurementRecord.GUID.String(), measurementRecord.Description)
+		previous = measurementRecords
 	}
+}
+
+// printRecords prints the records in the format selected by the flags.
+func printRecords(measurementRecords []fbpt.MEASUREMENT_RECORD) error {
+	switch {
+	case *trace:
+		// Timestamps are relative to the end of the platform reset.
+		return fbpt.WriteTraceEvent(os.Stdout, measurementRecords, time.Unix(0, 0))
+	case *edk2:
+		return fbpt.WriteEDK2Format(os.Stdout, measurementRecords)
+	case *ndjson:
+		return fbpt.WriteNDJSON(os.Stdout, measurementRecords)
+	case *sqlite != "":
+		id := *bootID
+		if id == "" {
+			b, err := os.ReadFile(bootIDPath)
+			if err != nil {
+				return fmt.Errorf("reading the boot ID, use -boot-id to set one: %w", err)
+			}
+			id = strings.TrimSpace(string(b))
+		}
+		return fbpt.WriteSQLite(*sqlite, measurementRecords, id)
+	case *chart:
+		return fbpt.WriteASCIIChart(os.Stdout, measurementRecords, chartWidth)
+	}
+
+	for i, measurementRecord := range measurementRecords {
+		printRecord(i, measurementRecord)
+	}
+	return nil
+}
 
+func printRecord(i int, measurementRecord fbpt.MEASUREMENT_RECORD) {
+	fmt.Printf("Index: %d,Hook Type: %s, Processor Identifier/APIC ID: %d, Timestamp: %d, Guid: %s, Description: %s\n", i, measurementRecord.HookType, measurementRecord.ProcessorIdentifier, measurementRecord.Timestamp, measurementRecord.GUID.String(), measurementRecord.Description)
 }
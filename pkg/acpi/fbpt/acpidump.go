@@ -0,0 +1,146 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/u-root/u-root/pkg/acpi"
+	"github.com/u-root/u-root/pkg/acpi/fpdt"
+)
+
+// dumpRegion is a table of an acpidump together with its physical address.
+type dumpRegion struct {
+	sig  string
+	addr uint64
+	data []byte
+}
+
+// dumpMemory translates physical addresses to the tables of an acpidump, so
+// that pointers between the tables can be followed as in /dev/mem.
+type dumpMemory []dumpRegion
+
+// ReadAt implements io.ReaderAt. Reads are served from the single table
+// holding off and come up short at its end.
+func (m dumpMemory) ReadAt(p []byte, off int64) (int, error) {
+	addr := uint64(off)
+	for _, r := range m {
+		if addr >= r.addr && addr-r.addr < uint64(len(r.data)) {
+			n := copy(p, r.data[addr-r.addr:])
+			if n < len(p) {
+				return n, io.EOF
+			}
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("address %#x is not part of the acpidump", addr)
+}
+
+// parseACPIDumpHeader parses a "SIG @ 0xADDRESS" table header line.
+func parseACPIDumpHeader(text string) (string, uint64, bool) {
+	if strings.Contains(text, ":") {
+		return "", 0, false
+	}
+	sig, addr, ok := strings.Cut(text, " @ ")
+	if !ok {
+		return "", 0, false
+	}
+	a, err := strconv.ParseUint(strings.TrimSpace(addr), 0, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return strings.TrimSpace(sig), a, true
+}
+
+// parseACPIDump parses the text output of the ACPICA acpidump tool: every
+// table starts with a "SIG @ 0xADDRESS" line followed by hexdump lines of
+// the form "OFFSET: XX XX ...  ASCII".
+func parseACPIDump(dump []byte) (dumpMemory, error) {
+	var mem dumpMemory
+	s := bufio.NewScanner(bytes.NewReader(dump))
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" {
+			continue
+		}
+		// Hexdump lines come first: their ASCII column shows the bytes
+		// 20 40 20 as " @ " too.
+		offset, rest, ok := strings.Cut(text, ": ")
+		o, err := strconv.ParseUint(offset, 16, 32)
+		if !ok || err != nil {
+			if sig, addr, ok := parseACPIDumpHeader(text); ok {
+				mem = append(mem, dumpRegion{sig: sig, addr: addr})
+			}
+			continue
+		}
+		if len(mem) == 0 {
+			continue
+		}
+		r := &mem[len(mem)-1]
+		if o != uint64(len(r.data)) {
+			return nil, fmt.Errorf("acpidump line %d: unexpected offset %q in table %s", line, offset, r.sig)
+		}
+		// The 16 hex bytes take 47 columns, the ASCII column follows.
+		if len(rest) > 47 {
+			rest = rest[:47]
+		}
+		for _, field := range strings.Fields(rest) {
+			b, err := strconv.ParseUint(field, 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("acpidump line %d: invalid byte %q in table %s", line, field, r.sig)
+			}
+			r.data = append(r.data, byte(b))
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(mem) == 0 {
+		return nil, fmt.Errorf("no tables found in acpidump")
+	}
+	return mem, nil
+}
+
+// FindFBPTInACPIDump locates the FPDT in dump, the text output of the ACPICA
+// acpidump tool, and returns the dynamic string event records of the FBPT it
+// points to along with its address. The FBPT pointer is followed within the
+// physical address space recorded in the dump, so the dump has to include the
+// FBPT as a table of its own. Like FindAllFBPTRecords, records that cannot be
+// decoded are skipped; use Scanner.ReadFBPTRecords to get them as warnings.
+func FindFBPTInACPIDump(dump []byte) (uint64, []MEASUREMENT_RECORD, error) {
+	mem, err := parseACPIDump(dump)
+	if err != nil {
+		return 0, nil, err
+	}
+	var table acpi.Table
+	for _, r := range mem {
+		if r.sig != "FPDT" {
+			continue
+		}
+		tables, err := acpi.NewRaw(r.data)
+		if err != nil {
+			return 0, nil, fmt.Errorf("FPDT in acpidump: %w", err)
+		}
+		table = tables[0]
+		break
+	}
+	if table == nil {
+		return 0, nil, fmt.Errorf("no FPDT found in acpidump")
+	}
+	FBPTAddr, err := fpdt.FindFBPTTableAdrr(table)
+	if err != nil {
+		return 0, nil, err
+	}
+	records, _, err := (&Scanner{}).ReadFBPTRecords(mem, FBPTAddr)
+	if err != nil {
+		return FBPTAddr, nil, fmt.Errorf("FBPT at %#x in acpidump: %w", FBPTAddr, err)
+	}
+	return FBPTAddr, records, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/acpi/fbpt/fbpttest"
+)
+
+// acpidumpTable renders data like the ACPICA acpidump tool.
+func acpidumpTable(sig string, addr uint64, data []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s @ 0x%016X\n", sig, addr)
+	for off := 0; off < len(data); off += 16 {
+		end := off + 16
+		if end > len(data) {
+			end = len(data)
+		}
+		var hex, ascii []string
+		for _, c := range data[off:end] {
+			hex = append(hex, fmt.Sprintf("%02X", c))
+			if c < 0x20 || c > 0x7e {
+				c = '.'
+			}
+			ascii = append(ascii, string(c))
+		}
+		fmt.Fprintf(&b, "    %04X: %-47s  %s\n", off, strings.Join(hex, " "), strings.Join(ascii, ""))
+	}
+	return b.String() + "\n"
+}
+
+func TestFindFBPTInACPIDump(t *testing.T) {
+	tables, err := fbpttest.Build(
+		fbpttest.BasicBootRecord(1, 2, 3, 4, 5),
+		fbpttest.DynamicRecord(MODULE_START_ID, 0, 100, [16]byte{}, "12 34 56"),
+		fbpttest.DynamicRecord(MODULE_END_ID, 0, 200, [16]byte{}, "12 34 56"),
+	)
+	if err != nil {
+		t.Fatalf("fbpttest.Build() = _, %v, want nil", err)
+	}
+	raw, err := dumpTable(tables.Mem, tables.FBPTAddr)
+	if err != nil {
+		t.Fatalf("dumpTable() = _, %v, want nil", err)
+	}
+	dump := acpidumpTable("FACP", 0x500, make([]byte, 40)) +
+		acpidumpTable("FPDT", fbpttest.FPDTAddr, tables.FPDT.Data()) +
+		acpidumpTable("FBPT", tables.FBPTAddr, raw)
+
+	addr, records, err := FindFBPTInACPIDump([]byte(dump))
+	if err != nil {
+		t.Fatalf("FindFBPTInACPIDump() = _, _, %v, want nil", err)
+	}
+	if addr != tables.FBPTAddr {
+		t.Errorf("FindFBPTInACPIDump() = %#x, _, _, want %#x", addr, tables.FBPTAddr)
+	}
+	if len(records) != 2 || records[0].Timestamp != 100 || records[1].Description != "12 34 56" {
+		t.Errorf("FindFBPTInACPIDump() = _, %+v, _, want the 2 dynamic records", records)
+	}
+
+	withoutFBPT := acpidumpTable("FPDT", fbpttest.FPDTAddr, tables.FPDT.Data())
+	if _, _, err := FindFBPTInACPIDump([]byte(withoutFBPT)); err == nil || !strings.Contains(err.Error(), "not part of the acpidump") {
+		t.Errorf("FindFBPTInACPIDump(no FBPT) = _, _, %v, want error about the missing FBPT", err)
+	}
+	if _, _, err := FindFBPTInACPIDump([]byte(acpidumpTable("FACP", 0x500, make([]byte, 40)))); err == nil {
+		t.Errorf("FindFBPTInACPIDump(no FPDT) = _, _, nil, want error")
+	}
+}
+
+func TestParseACPIDumpASCIIColumn(t *testing.T) {
+	// The ASCII column of these bytes reads like a table header.
+	data := []byte(" @ AAAAAAAAAAAAA @ 0x1000 ")
+	dump := acpidumpTable("SSDT", 0x500, data) + acpidumpTable("FACP", 0x600, make([]byte, 4))
+	if !strings.Contains(dump, "20 40 20 41") || !strings.Contains(dump, "  @ 0x1000") {
+		t.Fatalf("acpidumpTable() = %q, want hexdump lines with \" @ \" in the ASCII column", dump)
+	}
+	mem, err := parseACPIDump([]byte(dump))
+	if err != nil {
+		t.Fatalf("parseACPIDump() = _, %v, want nil", err)
+	}
+	if len(mem) != 2 || mem[0].sig != "SSDT" || string(mem[0].data) != string(data) || mem[1].sig != "FACP" || mem[1].addr != 0x600 {
+		t.Errorf("parseACPIDump() = %+v, want the SSDT and FACP tables", mem)
+	}
+}
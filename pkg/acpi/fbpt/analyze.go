@@ -0,0 +1,78 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// formatTimespan formats d like systemd-analyze does, e.g. 1min 2.345s,
+// 2.345s, 345ms or 45us.
+func formatTimespan(d time.Duration) string {
+	switch {
+	case d >= time.Minute:
+		return fmt.Sprintf("%dmin %.3fs", d/time.Minute, (d % time.Minute).Seconds())
+	case d >= time.Second:
+		return fmt.Sprintf("%.3fs", d.Seconds())
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%dms", d/time.Millisecond)
+	default:
+		return fmt.Sprintf("%dus", d/time.Microsecond)
+	}
+}
+
+// WriteAnalyze writes a report in the style of systemd-analyze: a first line
+// splitting the boot into the time spent in the firmware until the OS loader
+// was started and the time spent in the loader until it exited boot
+// services, as recorded in boot, followed by the phases of records, longest
+// first, like systemd-analyze blame. If the loader timestamps of boot are not
+// populated, the whole boot time is attributed to the firmware, and if boot
+// is not populated at all, the time spanned by records is.
+func WriteAnalyze(w io.Writer, boot EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD, records []MEASUREMENT_RECORD) error {
+	total := boot.BootDuration()
+	if total == 0 {
+		var first, last uint64
+		for i, record := range records {
+			if i == 0 || record.Timestamp < first {
+				first = record.Timestamp
+			}
+			if record.Timestamp > last {
+				last = record.Timestamp
+			}
+		}
+		total = time.Duration(last - first)
+	}
+
+	start := boot.OSLoaderStartImageStart
+	if boot.BootDuration() > 0 && start > boot.ResetEnd && start <= boot.ExitBootServicesExit {
+		firmware := time.Duration(start - boot.ResetEnd)
+		if _, err := fmt.Fprintf(w, "Startup finished in %s (firmware) + %s (loader) = %s\n",
+			formatTimespan(firmware), formatTimespan(total-firmware), formatTimespan(total)); err != nil {
+			return err
+		}
+	} else if _, err := fmt.Fprintf(w, "Startup finished in %s (firmware) = %s\n", formatTimespan(total), formatTimespan(total)); err != nil {
+		return err
+	}
+
+	phases := PairPhases(records)
+	if len(phases) == 0 {
+		return nil
+	}
+	sort.SliceStable(phases, func(i, j int) bool {
+		return phases[i].Duration() > phases[j].Duration()
+	})
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	for _, phase := range phases {
+		if _, err := fmt.Fprintf(w, "%12s %s\n", formatTimespan(phase.Duration()), phase.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
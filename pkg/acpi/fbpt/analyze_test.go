@@ -0,0 +1,74 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestFormatTimespan(t *testing.T) {
+	for _, tt := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 45 * time.Microsecond, want: "45us"},
+		{d: 345*time.Millisecond + 999*time.Microsecond, want: "345ms"},
+		{d: 2345 * time.Millisecond, want: "2.345s"},
+		{d: 62345 * time.Millisecond, want: "1min 2.345s"},
+	} {
+		if got := formatTimespan(tt.d); got != tt.want {
+			t.Errorf("formatTimespan(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestWriteAnalyze(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 100e6, "PEI"),
+		record(MODULE_END_ID, 400e6, "PEI"),
+		record(MODULE_START_ID, 500e6, "DXE"),
+		record(MODULE_END_ID, 1500e6, "DXE"),
+	}
+	boot := EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{ResetEnd: 50e6, OSLoaderStartImageStart: 1650e6, ExitBootServicesExit: 2050e6}
+	for _, tt := range []struct {
+		name string
+		boot EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD
+		want string
+	}{
+		{
+			name: "basic boot record",
+			boot: boot,
+			want: "Startup finished in 1.600s (firmware) + 400ms (loader) = 2.000s\n" +
+				"\n" +
+				"      1.000s DXE\n" +
+				"       300ms PEI\n",
+		},
+		{
+			name: "no loader timestamps",
+			boot: EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{ResetEnd: 50e6, ExitBootServicesExit: 2050e6},
+			want: "Startup finished in 2.000s (firmware) = 2.000s\n" +
+				"\n" +
+				"      1.000s DXE\n" +
+				"       300ms PEI\n",
+		},
+		{
+			name: "no basic boot record",
+			want: "Startup finished in 1.400s (firmware) = 1.400s\n" +
+				"\n" +
+				"      1.000s DXE\n" +
+				"       300ms PEI\n",
+		},
+	} {
+		var b bytes.Buffer
+		if err := WriteAnalyze(&b, tt.boot, records); err != nil {
+			t.Fatalf("WriteAnalyze(%s) = %v, want nil", tt.name, err)
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("WriteAnalyze(%s) = \n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
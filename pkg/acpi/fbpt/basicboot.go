@@ -0,0 +1,147 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// clockMismatchFactor is how far the latest record timestamp may lie beyond,
+// or fall short of, the latest basic boot record timestamp before
+// DetectClockMismatch flags the two as using different clocks. It is well
+// below the factor of 1000 between nanoseconds and microseconds, but leaves
+// room for tables only recording the early phases of the boot.
+const clockMismatchFactor = 100
+
+// basicBootRecordPayloadSize is the size of the basic boot record following
+// its header: a reserved uint32 and five uint64 timestamps.
+const basicBootRecordPayloadSize = 44
+
+// ErrBasicBootRecordNotFound is returned by ReadBasicBootRecord if the FBPT
+// has no firmware basic boot record.
+var ErrBasicBootRecordNotFound = errors.New("FBPT has no firmware basic boot record")
+
+// ReadBasicBootRecord returns the firmware basic boot record of the FBPT at
+// FBPTAddr, which holds the overall boot milestones. The walk stops at the
+// basic boot record, so this is much cheaper than FindAllFBPTRecords.
+func ReadBasicBootRecord(FBPTAddr uint64) (EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD, error) {
+	f, err := os.OpenFile(memDevice, os.O_RDONLY, 0)
+	if err != nil {
+		return EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{}, err
+	}
+	defer f.Close()
+	return readBasicBootRecord(f, FBPTAddr)
+}
+
+func readBasicBootRecord(mem io.ReaderAt, FBPTAddr uint64) (EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD, error) {
+	var record EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD
+	tablelength, err := verifyFBPTSignature(mem, FBPTAddr)
+	if err != nil {
+		return record, err
+	}
+	found := false
+	// The basic boot record usually comes first, so reading the whole
+	// table up front like Walk would only slow this down.
+	err = walkRecords(mem, FBPTAddr, tablelength, func(_ uint32, hdr EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, payload []byte) error {
+		if hdr.Type != EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_FIRMWARE_BASIC_BOOT {
+			return nil
+		}
+		var err error
+		if record, err = parseBasicBootRecord(hdr, payload); err != nil {
+			return err
+		}
+		found = true
+		return ErrStopWalk
+	}, nil)
+	if err != nil {
+		return record, err
+	}
+	if !found {
+		return record, ErrBasicBootRecordNotFound
+	}
+	return record, nil
+}
+
+func parseBasicBootRecord(hdr EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, payload []byte) (EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD, error) {
+	if len(payload) < basicBootRecordPayloadSize {
+		return EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{}, fmt.Errorf("firmware basic boot record too short: %d bytes", len(payload))
+	}
+	return EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{
+		PerformanceRecordHeader: hdr,
+		ResetEnd:                binary.LittleEndian.Uint64(payload[4:]),
+		OSLoaderLoadImageStart:  binary.LittleEndian.Uint64(payload[12:]),
+		OSLoaderStartImageStart: binary.LittleEndian.Uint64(payload[20:]),
+		ExitBootServicesEntry:   binary.LittleEndian.Uint64(payload[28:]),
+		ExitBootServicesExit:    binary.LittleEndian.Uint64(payload[36:]),
+	}, nil
+}
+
+// ExitBootServicesDuration returns the time spent in ExitBootServices, or 0
+// if the timestamps are not populated or inconsistent.
+func (b EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD) ExitBootServicesDuration() time.Duration {
+	if b.ExitBootServicesEntry == 0 || b.ExitBootServicesExit < b.ExitBootServicesEntry {
+		return 0
+	}
+	return time.Duration(b.ExitBootServicesExit - b.ExitBootServicesEntry)
+}
+
+// BootDuration returns the time from the end of the platform reset to the
+// handoff to the OS at the exit of ExitBootServices, or 0 if the timestamps
+// are not populated or inconsistent.
+func (b EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD) BootDuration() time.Duration {
+	if b.ExitBootServicesExit == 0 || b.ExitBootServicesExit < b.ResetEnd {
+		return 0
+	}
+	return time.Duration(b.ExitBootServicesExit - b.ResetEnd)
+}
+
+// OSLoaderPhase returns the records between OSLoaderLoadImageStart and
+// OSLoaderStartImageStart of boot, inclusive, i.e. those logged while the OS
+// loader was being loaded, and the duration of that window. It returns no
+// records and 0 if the timestamps are not populated or inconsistent.
+func OSLoaderPhase(boot EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD, records []MEASUREMENT_RECORD) ([]MEASUREMENT_RECORD, time.Duration) {
+	start, end := boot.OSLoaderLoadImageStart, boot.OSLoaderStartImageStart
+	if start == 0 || end < start {
+		return nil, 0
+	}
+	var window []MEASUREMENT_RECORD
+	for _, record := range records {
+		if record.Timestamp >= start && record.Timestamp <= end {
+			window = append(window, record)
+		}
+	}
+	return window, time.Duration(end - start)
+}
+
+// DetectClockMismatch reports whether the timestamps of records and boot are
+// apparently not on the same scale, as seen with firmware logging the records
+// in nanoseconds but the basic boot record in another unit, or the other way
+// around. This is the case if the latest record timestamp exceeds the latest
+// boot record timestamp by more than clockMismatchFactor times, or falls
+// short of it by that factor. Durations computed across both are meaningless
+// then. Without populated timestamps on either side it returns false.
+func DetectClockMismatch(boot EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD, records []MEASUREMENT_RECORD) bool {
+	var bootEnd uint64
+	for _, ts := range []uint64{boot.ResetEnd, boot.OSLoaderLoadImageStart, boot.OSLoaderStartImageStart, boot.ExitBootServicesEntry, boot.ExitBootServicesExit} {
+		if ts > bootEnd {
+			bootEnd = ts
+		}
+	}
+	var recordsEnd uint64
+	for _, record := range records {
+		if record.Timestamp > recordsEnd {
+			recordsEnd = record.Timestamp
+		}
+	}
+	if bootEnd == 0 || recordsEnd == 0 {
+		return false
+	}
+	return recordsEnd/clockMismatchFactor > bootEnd || bootEnd/clockMismatchFactor > recordsEnd
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/u-root/u-root/pkg/acpi/fbpt/fbpttest"
+)
+
+func TestReadBasicBootRecord(t *testing.T) {
+	records := [][]byte{
+		dynamicRecord(MODULE_START_ID, 100, "PEI"),
+		fbpttest.BasicBootRecord(1, 2, 3, 4, 5),
+	}
+	for i := 0; i < 100; i++ {
+		records = append(records, dynamicRecord(MODULE_END_ID, 200, "PEI"))
+	}
+	mem := fakeMem(records...)
+	r := &countingReader{ReaderAt: bytes.NewReader(mem)}
+	got, err := readBasicBootRecord(r, tableAddr)
+	if err != nil {
+		t.Fatalf("readBasicBootRecord() = _, %v, want nil", err)
+	}
+	want := EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{
+		PerformanceRecordHeader: EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER{
+			Type:     EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_FIRMWARE_BASIC_BOOT,
+			Length:   48,
+			Revision: EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_REVISION_FIRMWARE_BASIC_BOOT,
+		},
+		ResetEnd:                1,
+		OSLoaderLoadImageStart:  2,
+		OSLoaderStartImageStart: 3,
+		ExitBootServicesEntry:   4,
+		ExitBootServicesExit:    5,
+	}
+	if got != want {
+		t.Errorf("readBasicBootRecord() = %+v, want %+v", got, want)
+	}
+	// The signature, the first header and one read for each record up to
+	// the basic boot record, the rest of the table is never read.
+	if r.calls != 4 {
+		t.Errorf("readBasicBootRecord() made %d reads, want 4", r.calls)
+	}
+
+	if _, err := readBasicBootRecord(bytes.NewReader(fakeMem(dynamicRecord(MODULE_START_ID, 100, "PEI"))), tableAddr); err != ErrBasicBootRecordNotFound {
+		t.Errorf("readBasicBootRecord() = _, %v, want %v", err, ErrBasicBootRecordNotFound)
+	}
+}
+
+func TestExitBootServicesDuration(t *testing.T) {
+	for _, tt := range []struct {
+		entry, exit uint64
+		want        time.Duration
+	}{
+		{entry: 1000, exit: 3500, want: 2500 * time.Nanosecond},
+		{entry: 0, exit: 0, want: 0},
+		{entry: 0, exit: 3500, want: 0},
+		{entry: 1000, exit: 0, want: 0},
+	} {
+		b := EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{ExitBootServicesEntry: tt.entry, ExitBootServicesExit: tt.exit}
+		if got := b.ExitBootServicesDuration(); got != tt.want {
+			t.Errorf("ExitBootServicesDuration() with entry %d and exit %d = %v, want %v", tt.entry, tt.exit, got, tt.want)
+		}
+	}
+}
+
+func TestBootDuration(t *testing.T) {
+	for _, tt := range []struct {
+		resetEnd, exit uint64
+		want           time.Duration
+	}{
+		{resetEnd: 1000, exit: 5000, want: 4000 * time.Nanosecond},
+		{resetEnd: 1000, exit: 0, want: 0},
+		{resetEnd: 6000, exit: 5000, want: 0},
+	} {
+		b := EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{ResetEnd: tt.resetEnd, ExitBootServicesExit: tt.exit}
+		if got := b.BootDuration(); got != tt.want {
+			t.Errorf("BootDuration() with reset end %d and exit %d = %v, want %v", tt.resetEnd, tt.exit, got, tt.want)
+		}
+	}
+}
+
+func TestOSLoaderPhase(t *testing.T) {
+	boot := EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{OSLoaderLoadImageStart: 2000, OSLoaderStartImageStart: 2500}
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 1000, "DXE"),
+		record(MODULE_LOADIMAGE_START_ID, 2000, "u-root"),
+		record(MODULE_LOADIMAGE_END_ID, 2400, "u-root"),
+		record(MODULE_START_ID, 3000, "BDS"),
+	}
+	window, d := OSLoaderPhase(boot, records)
+	if d != 500*time.Nanosecond {
+		t.Errorf("OSLoaderPhase() = _, %v, want %v", d, 500*time.Nanosecond)
+	}
+	if len(window) != 2 || window[0].Timestamp != 2000 || window[1].Timestamp != 2400 {
+		t.Errorf("OSLoaderPhase() = %v, _, want the records at 2000 and 2400", window)
+	}
+
+	if window, d := OSLoaderPhase(EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{OSLoaderLoadImageStart: 3000, OSLoaderStartImageStart: 2500}, records); window != nil || d != 0 {
+		t.Errorf("OSLoaderPhase() with inverted window = %v, %v, want nil, 0", window, d)
+	}
+}
+
+func TestDetectClockMismatch(t *testing.T) {
+	// A 2s boot in nanoseconds.
+	boot := EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{ResetEnd: 1e6, ExitBootServicesEntry: 1.9e9, ExitBootServicesExit: 2e9}
+	for _, tt := range []struct {
+		name string
+		boot EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD
+		last uint64
+		want bool
+	}{
+		{name: "nanoseconds", boot: boot, last: 1.8e9, want: false},
+		{name: "early phases only", boot: boot, last: 1e8, want: false},
+		{name: "microseconds", boot: boot, last: 1.8e6, want: true},
+		{name: "picoseconds", boot: boot, last: 1.8e12, want: true},
+		{name: "unpopulated boot record", last: 1.8e6, want: false},
+		{name: "no record timestamps", boot: boot, last: 0, want: false},
+	} {
+		records := []MEASUREMENT_RECORD{record(MODULE_START_ID, 0, "SEC"), record(MODULE_END_ID, tt.last, "BDS")}
+		if got := DetectClockMismatch(tt.boot, records); got != tt.want {
+			t.Errorf("DetectClockMismatch(%s) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
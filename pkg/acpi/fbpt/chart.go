@@ -0,0 +1,76 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// chartLabelWidth is the number of columns reserved for the labels of
+// WriteASCIIChart, longer labels are truncated.
+const chartLabelWidth = 24
+
+// chartLabel returns the label of phase truncated or padded to
+// chartLabelWidth columns.
+func chartLabel(phase PhasePair) string {
+	label := []rune(phase.Name())
+	if len(label) > chartLabelWidth {
+		label = append(label[:chartLabelWidth-1], '~')
+	}
+	return fmt.Sprintf("%-*s", chartLabelWidth, string(label))
+}
+
+// WriteASCIIChart writes a gantt-like chart of the phases found in records,
+// one labeled bar per phase. The bars are width columns wide for the time
+// between the start of the first and the end of the last phase, and are
+// positioned by the start of their phase relative to the first one. Every
+// phase gets at least one column.
+func WriteASCIIChart(w io.Writer, records []MEASUREMENT_RECORD, width int) error {
+	if width < 1 {
+		return fmt.Errorf("chart width %d is not positive", width)
+	}
+	phases := PairPhases(records)
+	if len(phases) == 0 {
+		return nil
+	}
+
+	first, last := phases[0].Start.Timestamp, phases[0].End.Timestamp
+	for _, phase := range phases {
+		if phase.Start.Timestamp < first {
+			first = phase.Start.Timestamp
+		}
+		if phase.End.Timestamp > last {
+			last = phase.End.Timestamp
+		}
+	}
+	span := float64(last - first)
+	column := func(ts uint64) int {
+		if span == 0 || ts < first {
+			return 0
+		}
+		return int(float64(ts-first) / span * float64(width))
+	}
+
+	for _, phase := range phases {
+		start := column(phase.Start.Timestamp)
+		if start >= width {
+			start = width - 1
+		}
+		end := column(phase.End.Timestamp)
+		if end <= start {
+			end = start + 1
+		}
+		if end > width {
+			end = width
+		}
+		bar := strings.Repeat(" ", start) + strings.Repeat("#", end-start) + strings.Repeat(" ", width-end)
+		if _, err := fmt.Fprintf(w, "%s |%s| %v\n", chartLabel(phase), bar, phase.Duration()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
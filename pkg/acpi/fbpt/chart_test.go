@@ -0,0 +1,40 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteASCIIChart(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 1000, "PEI"),
+		record(MODULE_END_ID, 1010, "PEI"),
+		record(PERF_FUNCTION_START_ID, 1010, "A very long description of the DXE phase"),
+		record(PERF_FUNCTION_END_ID, 1020, "A very long description of the DXE phase"),
+	}
+	var buf bytes.Buffer
+	if err := WriteASCIIChart(&buf, records, 10); err != nil {
+		t.Fatalf("WriteASCIIChart() = %v, want nil", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("WriteASCIIChart() wrote %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []string{
+		"PEI                      |#####     | 10ns",
+		"A very long description~ |     #####| 10ns",
+	} {
+		if lines[i] != want {
+			t.Errorf("WriteASCIIChart() line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+
+	if err := WriteASCIIChart(&buf, records, 0); err == nil {
+		t.Errorf("WriteASCIIChart(width 0) = nil, want error")
+	}
+}
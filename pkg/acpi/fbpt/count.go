@@ -0,0 +1,44 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"fmt"
+	"io"
+)
+
+// CountRecordsByType returns the number of records of each type in the FBPT
+// at addr in r. Only the record headers are read and the payloads skipped,
+// which makes this a much cheaper structural probe than FindAllFBPTRecords.
+// Like Walk, it fails on the first record with an invalid length.
+func CountRecordsByType(r io.ReaderAt, addr uint64) (map[uint16]int, error) {
+	tablelength, err := verifyFBPTSignature(r, addr)
+	if err != nil {
+		return nil, err
+	}
+	if tablelength < EFI_ACPI_5_0_FBPT_HEADER_SIZE {
+		return nil, fmt.Errorf("FBPT table length %d is smaller than its header", tablelength)
+	}
+	recordsLength := tablelength - EFI_ACPI_5_0_FBPT_HEADER_SIZE
+	recordsAddr := addr + EFI_ACPI_5_0_FBPT_HEADER_SIZE
+
+	counts := make(map[uint16]int)
+	var header [EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE]byte
+	for offset := uint32(0); offset < recordsLength; {
+		if recordsLength-offset < EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE {
+			return nil, fmt.Errorf("record header at table offset %d is truncated", offset)
+		}
+		if err := readFullAt(r, header[:], recordsAddr+uint64(offset)); err != nil {
+			return nil, err
+		}
+		hdr := parseRecordHeader(header[:])
+		if hdr.Length < EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE || uint32(hdr.Length) > recordsLength-offset {
+			return nil, fmt.Errorf("record at table offset %d has invalid length %d", offset, hdr.Length)
+		}
+		counts[hdr.Type]++
+		offset += uint32(hdr.Length)
+	}
+	return counts, nil
+}
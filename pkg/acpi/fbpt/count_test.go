@@ -0,0 +1,45 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/acpi/fbpt/fbpttest"
+)
+
+func TestCountRecordsByType(t *testing.T) {
+	mem := fakeMem(
+		fbpttest.BasicBootRecord(1, 2, 3, 4, 5),
+		dynamicRecord(MODULE_START_ID, 10, "PEI"),
+		otherRecord(0x0003, 0x10),
+		dynamicRecord(MODULE_END_ID, 20, "PEI"),
+	)
+	r := &countingReader{ReaderAt: bytes.NewReader(mem)}
+	counts, err := CountRecordsByType(r, tableAddr)
+	if err != nil {
+		t.Fatalf("CountRecordsByType() = _, %v, want nil", err)
+	}
+	want := map[uint16]int{
+		EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_FIRMWARE_BASIC_BOOT: 1,
+		FPDT_DYNAMIC_STRING_EVENT_RECORD_IDENTIFIER:               2,
+		0x0003: 1,
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountRecordsByType() = %v, want %v", counts, want)
+	}
+	// One read for the table header and one per record header.
+	if r.calls != 5 {
+		t.Errorf("CountRecordsByType() made %d reads, want 5", r.calls)
+	}
+
+	broken := otherRecord(0x0003, 0x10)
+	broken[2] = 0
+	if _, err := CountRecordsByType(bytes.NewReader(fakeMem(broken)), tableAddr); err == nil {
+		t.Errorf("CountRecordsByType(zero length record) = _, nil, want error")
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DescriptionFields parses descriptions of the form "PEI:FSP-M;0x1234", in
+// which fields are separated by semicolons and each field is a key and value
+// separated by a colon or an equals sign. Fields without a key are stored
+// under their zero based position, e.g. "1" for "0x1234" above. An empty map
+// is returned if no field has a key.
+func (m MEASUREMENT_RECORD) DescriptionFields() map[string]string {
+	fields := make(map[string]string)
+	keyed := false
+	for i, field := range strings.Split(m.Description, ";") {
+		if j := strings.IndexAny(field, ":="); j > 0 {
+			fields[field[:j]] = field[j+1:]
+			keyed = true
+			continue
+		}
+		if field != "" {
+			fields[strconv.Itoa(i)] = field
+		}
+	}
+	if !keyed {
+		return map[string]string{}
+	}
+	return fields
+}
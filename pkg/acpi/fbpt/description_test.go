@@ -0,0 +1,30 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescriptionFields(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		want        map[string]string
+	}{
+		{description: "PEI:FSP-M;0x1234", want: map[string]string{"PEI": "FSP-M", "1": "0x1234"}},
+		{description: "phase=DXE;driver=PciBus", want: map[string]string{"phase": "DXE", "driver": "PciBus"}},
+		{description: "url=http://host:80", want: map[string]string{"url": "http://host:80"}},
+		{description: "DxeCore", want: map[string]string{}},
+		{description: "a;b", want: map[string]string{}},
+		{description: ":value", want: map[string]string{}},
+		{description: "", want: map[string]string{}},
+	} {
+		got := MEASUREMENT_RECORD{Description: tt.description}.DescriptionFields()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DescriptionFields(%q) = %v, want %v", tt.description, got, tt.want)
+		}
+	}
+}
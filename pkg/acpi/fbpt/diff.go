@@ -0,0 +1,25 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+// NewRecords returns the records of cur that are not in prev, in the order
+// they appear in cur. It is meant for comparing two scans of the same FBPT,
+// to which firmware only ever appends. Identical records are matched by
+// count, so a repeated record is new if cur has more copies than prev.
+func NewRecords(prev, cur []MEASUREMENT_RECORD) []MEASUREMENT_RECORD {
+	seen := make(map[MEASUREMENT_RECORD]int, len(prev))
+	for _, record := range prev {
+		seen[record]++
+	}
+	var added []MEASUREMENT_RECORD
+	for _, record := range cur {
+		if seen[record] > 0 {
+			seen[record]--
+			continue
+		}
+		added = append(added, record)
+	}
+	return added
+}
@@ -0,0 +1,31 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewRecords(t *testing.T) {
+	prev := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 10, "PEI"),
+		record(MODULE_END_ID, 20, "PEI"),
+	}
+	cur := append(append([]MEASUREMENT_RECORD{}, prev...),
+		record(MODULE_END_ID, 20, "PEI"),
+		record(MODULE_START_ID, 30, "DXE"),
+	)
+	want := []MEASUREMENT_RECORD{
+		record(MODULE_END_ID, 20, "PEI"),
+		record(MODULE_START_ID, 30, "DXE"),
+	}
+	if got := NewRecords(prev, cur); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewRecords() = %+v, want %+v", got, want)
+	}
+	if got := NewRecords(cur, prev); len(got) != 0 {
+		t.Errorf("NewRecords(cur, prev) = %+v, want none", got)
+	}
+}
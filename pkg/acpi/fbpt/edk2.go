@@ -0,0 +1,34 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteEDK2Format writes the phases found in records in the layout of the raw
+// trace list printed by EDK2's Dp shell command (dp -R), so that it can be
+// compared with firmware side logs. Dp's module handle is not part of the
+// FBPT, the module GUID is printed in its place. Timestamps are printed as
+// raw hex values like Dp does.
+func WriteEDK2Format(w io.Writer, records []MEASUREMENT_RECORD) error {
+	if _, err := fmt.Fprintf(w, "\n==[ Raw Trace List ]==\n\n%5s  %-36s %-24s %16s %16s %9s\n%s\n",
+		"---", "Module GUID", "Token (String)", "Start", "Stop", "ID", strings.Repeat("-", 112)); err != nil {
+		return err
+	}
+	for i, phase := range PairPhases(records) {
+		token := phase.Start.Description
+		if len(token) > 24 {
+			token = token[:24]
+		}
+		if _, err := fmt.Fprintf(w, "%5d: %-36s %-24s %16X %16X %9d\n",
+			i+1, phase.Start.GUID.String(), token, phase.Start.Timestamp, phase.End.Timestamp, phase.Start.HookID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
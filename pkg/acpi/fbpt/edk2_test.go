@@ -0,0 +1,39 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteEDK2Format(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 0x1000, "PEI"),
+		record(PERF_INMODULE_START_ID, 0x1800, "a token that is far too long to fit"),
+		record(PERF_INMODULE_END_ID, 0x1900, ""),
+		record(MODULE_END_ID, 0x2000, "PEI"),
+	}
+	var buf bytes.Buffer
+	if err := WriteEDK2Format(&buf, records); err != nil {
+		t.Fatalf("WriteEDK2Format() = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("WriteEDK2Format() wrote %q, want 6 lines", buf.String())
+	}
+	if lines[0] != "==[ Raw Trace List ]==" {
+		t.Errorf("WriteEDK2Format() header = %q, want %q", lines[0], "==[ Raw Trace List ]==")
+	}
+	want := "    1: 00000000-0000-0000-0000-000000000000 PEI                                  1000             2000         1"
+	if lines[4] != want {
+		t.Errorf("WriteEDK2Format() line 4 = %q, want %q", lines[4], want)
+	}
+	if !strings.Contains(lines[5], " a token that is far too  ") {
+		t.Errorf("WriteEDK2Format() line 5 = %q, want truncated token", lines[5])
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/u-root/u-root/pkg/uefivars"
+)
+
+const (
+	// EDK2's FirmwarePerformanceDxe saves the table pointers in this variable,
+	// see edk2: /MdeModulePkg/Include/Guid/FirmwarePerformance.h
+	firmwarePerformanceVarGUID = "c095791a-3001-47b2-80c9-eac7319f2fa4"
+	firmwarePerformanceVarName = "FirmwarePerformance"
+)
+
+// based on struct definition found in edk2: /MdeModulePkg/Include/Guid/FirmwarePerformance.h
+type FIRMWARE_PERFORMANCE_VARIABLE struct {
+	BootPerformanceTablePointer uint64
+	S3PerformanceTablePointer   uint64
+}
+
+// FBPTAddrFromEFIVars returns the FBPT address recorded in the EDK2
+// FirmwarePerformance EFI variable, and whether the variable was found. It is
+// a second source for the address when the ACPI FPDT is unreliable.
+func FBPTAddrFromEFIVars() (uint64, bool) {
+	v, err := uefivars.ReadVar(firmwarePerformanceVarGUID, firmwarePerformanceVarName)
+	if err != nil {
+		return 0, false
+	}
+	var perf FIRMWARE_PERFORMANCE_VARIABLE
+	if err := binary.Read(bytes.NewReader(v.Data), binary.LittleEndian, &perf); err != nil {
+		return 0, false
+	}
+	return perf.BootPerformanceTablePointer, perf.BootPerformanceTablePointer != 0
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/uefivars"
+)
+
+func TestFBPTAddrFromEFIVars(t *testing.T) {
+	defer func(dir string) { uefivars.EfiVarDir = dir }(uefivars.EfiVarDir)
+	uefivars.EfiVarDir = t.TempDir()
+
+	if _, ok := FBPTAddrFromEFIVars(); ok {
+		t.Errorf("FBPTAddrFromEFIVars() = _, true, want false without variable")
+	}
+
+	dir := filepath.Join(uefivars.EfiVarDir, firmwarePerformanceVarName+"-"+firmwarePerformanceVarGUID)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte{0x00, 0x10, 0x8f, 0x7f, 0, 0, 0, 0, 0x00, 0x20, 0x8f, 0x7f, 0, 0, 0, 0}
+	if err := os.WriteFile(filepath.Join(dir, "data"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	addr, ok := FBPTAddrFromEFIVars()
+	if !ok || addr != 0x7f8f1000 {
+		t.Errorf("FBPTAddrFromEFIVars() = %#x, %t, want 0x7f8f1000, true", addr, ok)
+	}
+
+	// The variable holds both pointers, a shorter one is malformed.
+	if err := os.WriteFile(filepath.Join(dir, "data"), data[:8], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if addr, ok := FBPTAddrFromEFIVars(); ok {
+		t.Errorf("FBPTAddrFromEFIVars() with truncated variable = %#x, true, want false", addr)
+	}
+}
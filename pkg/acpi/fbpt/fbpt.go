@@ -5,10 +5,13 @@ package fbpt
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
+	"log"
+	"math"
 	"os"
+	"strings"
 
-	"github.com/u-root/u-root/pkg/acpi/fpdt"
 	"github.com/u-root/u-root/pkg/uefivars"
 )
 
@@ -22,8 +25,26 @@ const (
 
 	// maximum number of FBPTPerfRecords to return in 'FindAllFBPTRecords'
 	maxNumberOfFBPTPerfRecords = 2000
+	// maxFinalRecordOverrun is how many bytes the last record may overrun the
+	// table, a common firmware off-by-one, and still be decoded.
+	maxFinalRecordOverrun = 8
 
-	FPDT_DYNAMIC_STRING_EVENT_RECORD_IDENTIFIER = 0x1011
+	EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_FIRMWARE_BASIC_BOOT     = 0x0002
+	EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_REVISION_FIRMWARE_BASIC_BOOT = 0x02
+
+	// record types found in edk2: /MdeModulePkg/Include/Guid/ExtendedFirmwarePerformance.h
+	FPDT_GUID_EVENT_TYPE              = 0x1010
+	FPDT_DYNAMIC_STRING_EVENT_TYPE    = 0x1011
+	FPDT_DUAL_GUID_STRING_EVENT_TYPE  = 0x1012
+	FPDT_GUID_QWORD_EVENT_TYPE        = 0x1013
+	FPDT_GUID_QWORD_STRING_EVENT_TYPE = 0x1014
+	FPDT_RECORD_REVISION_1            = 0x01
+
+	FPDT_DYNAMIC_STRING_EVENT_RECORD_IDENTIFIER = FPDT_DYNAMIC_STRING_EVENT_TYPE
+	FPDT_DYNAMIC_STRING_EVENT_RECORD_REVISION   = FPDT_RECORD_REVISION_1
+
+	// size of HookType, ProcessorIdentifier, Timestamp and GUID preceding the description
+	dynamicRecordFixedSize = 30
 
 	MODULE_START_ID            = 0x01
 	MODULE_END_ID              = 0x02
@@ -72,6 +93,42 @@ var eventTypeMap = map[uint16]string{
 	PERF_CROSSMODULE_END_ID:   "PERF_CROSSMODULE_END_ID",
 }
 
+// HookCategories groups the hook IDs by what they measure: the lifecycle of
+// modules, driver binding operations, event and callback notifications,
+// functions, and scopes inside or across modules.
+var HookCategories = map[uint16]string{
+	MODULE_START_ID:           "module",
+	MODULE_END_ID:             "module",
+	MODULE_LOADIMAGE_START_ID: "module",
+	MODULE_LOADIMAGE_END_ID:   "module",
+
+	MODULE_DB_START_ID:         "database",
+	MODULE_DB_END_ID:           "database",
+	MODULE_DB_SUPPORT_START_ID: "database",
+	MODULE_DB_SUPPORT_END_ID:   "database",
+	MODULE_DB_STOP_START_ID:    "database",
+	MODULE_DB_STOP_END_ID:      "database",
+
+	PERF_EVENTSIGNAL_START_ID: "callback",
+	PERF_EVENTSIGNAL_END_ID:   "callback",
+	PERF_CALLBACK_START_ID:    "callback",
+	PERF_CALLBACK_END_ID:      "callback",
+
+	PERF_FUNCTION_START_ID: "function",
+	PERF_FUNCTION_END_ID:   "function",
+
+	PERF_INMODULE_START_ID:    "module-scope",
+	PERF_INMODULE_END_ID:      "module-scope",
+	PERF_CROSSMODULE_START_ID: "module-scope",
+	PERF_CROSSMODULE_END_ID:   "module-scope",
+}
+
+// HookCategory returns the category of the hook ID, see HookCategories, or
+// the empty string for unknown IDs.
+func HookCategory(id uint16) string {
+	return HookCategories[id]
+}
+
 // based on struct definition found in edk2: /MdePkg/Include/IndustryStandard/Acpi50.h
 type EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER struct {
 	Type     uint16
@@ -90,108 +147,387 @@ type EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD struct {
 }
 
 type MEASUREMENT_RECORD struct {
+	// HookID is the raw hook type, one of the MODULE_* and PERF_* IDs
+	HookID uint16
+	// HookType is the name of HookID
 	HookType            string
 	ProcessorIdentifier uint32
 	Timestamp           uint64
 	GUID                uefivars.MixedGUID
 	Description         string
+	// DriverName is the name of the driver with file GUID GUID, see
+	// AnnotateWithFirmwareVolume
+	DriverName string
+	// Phase is the name of the innermost module enclosing the record, see
+	// AssignPhases
+	Phase string
+	// Source is the index of the capture the record came from, see MergeTimelines
+	Source int
 }
 
-func verifyFBPTSignature(mem io.ReadSeeker, fbptAddr uint64) (uint32, error) {
+// ErrStopWalk can be returned by the Walk callback to stop the walk early
+// without Walk returning an error.
+var ErrStopWalk = errors.New("stop walking the FBPT")
 
-	// Read & confirm FBPT struct signature
-	if _, err := mem.Seek(int64(fbptAddr), io.SeekStart); err != nil {
-		return 0, err
+// readFullAt reads exactly len(buf) bytes from r at offset off. Reads from
+// /dev/mem can return fewer bytes than requested near region boundaries, so
+// short reads are retried until buf is full or no more data is available.
+func readFullAt(r io.ReaderAt, buf []byte, off uint64) error {
+	if _, err := io.ReadFull(io.NewSectionReader(r, int64(off), int64(len(buf))), buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("reading %d bytes at %#x: %w", len(buf), off, err)
 	}
-	// Read as slices
-	var fbptSig [4]byte
-	if _, err := io.ReadFull(mem, fbptSig[:]); err != nil {
+	return nil
+}
+
+func verifyFBPTSignature(mem io.ReaderAt, fbptAddr uint64) (uint32, error) {
+	return verifyFBPTSignatureRelaxed(mem, fbptAddr, nil)
+}
+
+// verifyFBPTSignatureRelaxed is like verifyFBPTSignature but, if warn is not
+// nil, also accepts a signature that only matches after trimming white space
+// and NUL padding and upper casing it, which it reports to warn.
+func verifyFBPTSignatureRelaxed(mem io.ReaderAt, fbptAddr uint64, warn func(error)) (uint32, error) {
+
+	// Read & confirm FBPT struct signature and length in one go
+	var fbptHeader [EFI_ACPI_5_0_FBPT_HEADER_SIZE]byte
+	if err := readFullAt(mem, fbptHeader[:], fbptAddr); err != nil {
 		return 0, err
 	}
 
-	if string(fbptSig[:]) != FBPTStructureSig {
-		return 0, errors.New("FBPT structure signature check failed. Expected: FBPT, Got: " + string(fbptSig[:]))
+	if sig := string(fbptHeader[:4]); sig != FBPTStructureSig {
+		if warn == nil || strings.ToUpper(strings.Trim(sig, " \t\r\n\x00")) != FBPTStructureSig {
+			return 0, errors.New("FBPT structure signature check failed. Expected: FBPT, Got: " + sig)
+		}
+		warn(fmt.Errorf("accepting non-conformant FBPT structure signature %q", sig))
 	}
 
-	var fbptLength [4]byte
-	if _, err := io.ReadFull(mem, fbptLength[:]); err != nil {
-		return 0, err
-	}
+	return binary.LittleEndian.Uint32(fbptHeader[4:]), nil
+}
 
-	return binary.LittleEndian.Uint32(fbptLength[:]), nil
+// DumpTable returns the raw bytes of the FBPT at FBPTAddr, including its
+// header, e.g. to attach them to a firmware bug report.
+func DumpTable(FBPTAddr uint64) ([]byte, error) {
+	f, err := os.OpenFile(memDevice, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return dumpTable(f, FBPTAddr)
 }
 
-func FindAllFBPTRecords(FBPTAddr uint64) (int, []MEASUREMENT_RECORD, error) {
+func dumpTable(mem io.ReaderAt, FBPTAddr uint64) ([]byte, error) {
+	tablelength, err := verifyFBPTSignature(mem, FBPTAddr)
+	if err != nil {
+		return nil, err
+	}
+	if tablelength < EFI_ACPI_5_0_FBPT_HEADER_SIZE || tablelength > maxPlausibleFBPTLength {
+		return nil, fmt.Errorf("FBPT table length %d is implausible", tablelength)
+	}
+	table := make([]byte, tablelength)
+	if err := readFullAt(mem, table, FBPTAddr); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
 
-	var f *os.File
-	var err error
-	if f, err = os.OpenFile(memDevice, os.O_RDONLY, 0); err != nil {
-		return 0, nil, err
+// Walk calls fn with the header and payload of every record in the FBPT at
+// addr, whatever its type. The payload is only valid until fn returns. If fn
+// returns an error the walk stops and, unless it is ErrStopWalk, Walk returns
+// that error.
+func Walk(r io.ReaderAt, addr uint64, fn func(hdr EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, payload []byte) error) error {
+	tablelength, err := verifyFBPTSignature(r, addr)
+	if err != nil {
+		return err
 	}
-	defer f.Close()
+	return walk(r, addr, tablelength, func(_ uint32, hdr EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, payload []byte) error {
+		return fn(hdr, payload)
+	}, nil)
+}
 
-	var tablelength uint32
-	if tablelength, err = verifyFBPTSignature(f, FBPTAddr); err != nil {
-		return 0, nil, err
+// walk implements Walk for the FBPT at addr, whose signature has been checked
+// and whose header declares tablelength, additionally passing each record's
+// offset within the table to fn. Records are located by their absolute offset from the table
+// start. If warn is not nil, a record with an invalid length does not end
+// the walk: walk searches the rest of the table for the next plausible record,
+// reports what it skipped to warn and carries on from there. A final record
+// overrunning the table by at most maxFinalRecordOverrun bytes is then passed
+// to fn with the part of its payload that fits in the table, and trailing
+// bytes too short for a record header are reported to warn and ignored.
+func walk(r io.ReaderAt, addr uint64, tablelength uint32, fn func(offset uint32, hdr EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, payload []byte) error, warn func(error)) error {
+	return walkRecords(newTableReader(r, addr, tablelength), addr, tablelength, fn, warn)
+}
+
+// walkRecords is like walk, but reads every record from r on its own instead
+// of the whole table up front, which is cheaper for walks that stop early.
+func walkRecords(r io.ReaderAt, addr uint64, tablelength uint32, fn func(offset uint32, hdr EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, payload []byte) error, warn func(error)) error {
+	if tablelength < EFI_ACPI_5_0_FBPT_HEADER_SIZE {
+		return fmt.Errorf("FBPT table length %d is smaller than its header", tablelength)
 	}
+	recordsLength := tablelength - EFI_ACPI_5_0_FBPT_HEADER_SIZE
+	recordsAddr := addr + EFI_ACPI_5_0_FBPT_HEADER_SIZE
 
-	// iterate through FBPT table
-	var measurementRecords = make([]MEASUREMENT_RECORD, maxNumberOfFBPTPerfRecords)
-	var index int
 	var tableBytesRead uint32
 	var HeaderInfo EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER
-	for tableBytesRead < (tablelength - EFI_ACPI_5_0_FBPT_HEADER_SIZE) && index < maxNumberOfFBPTPerfRecords{
-		if HeaderInfo.Type, HeaderInfo.Length, _, err = fpdt.ReadFPDTRecordHeader(f); err != nil {
-			return index, nil, err
+
+	// Every read fetches the rest of the current record together with the
+	// header of the next one, so each record costs a single read.
+	var buf [math.MaxUint8 + EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE]byte
+	var header []byte
+	if recordsLength >= EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE {
+		header = buf[:EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE]
+		if err := readFullAt(r, header, recordsAddr); err != nil {
+			return err
 		}
-		if HeaderInfo.Type == FPDT_DYNAMIC_STRING_EVENT_RECORD_IDENTIFIER {
-			if measurementRecords[index], err = readFirmwarePerformanceDataTableDynamicRecord(f, HeaderInfo.Length); err != nil {
-				return index, nil, err
+	}
+	for tableBytesRead < recordsLength {
+		// A tail too short for a header cannot hold a record.
+		if remaining := recordsLength - tableBytesRead; remaining < EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE {
+			err := fmt.Errorf("%d trailing bytes at table offset %d are too short for a record header", remaining, tableBytesRead)
+			if warn == nil {
+				return err
+			}
+			warn(err)
+			return nil
+		}
+		HeaderInfo = parseRecordHeader(header)
+		// Without a usable length there is no way to find the next record.
+		if HeaderInfo.Length < EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE || uint32(HeaderInfo.Length) > recordsLength-tableBytesRead {
+			err := fmt.Errorf("record at table offset %d has invalid length %d", tableBytesRead, HeaderInfo.Length)
+			if warn == nil {
+				return err
+			}
+			remaining := recordsLength - tableBytesRead
+			if remaining >= EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE && uint32(HeaderInfo.Length)-remaining <= maxFinalRecordOverrun {
+				warn(fmt.Errorf("%w, overrunning the table by %d bytes, decoding the remaining %d bytes", err, uint32(HeaderInfo.Length)-remaining, remaining))
+				payload := buf[:remaining-EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE]
+				if err := readFullAt(r, payload, recordsAddr+uint64(tableBytesRead)+EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE); err != nil {
+					return err
+				}
+				if err := fn(tableBytesRead, HeaderInfo, payload); err != nil && err != ErrStopWalk {
+					return err
+				}
+				return nil
+			}
+			rest := make([]byte, remaining)
+			if err := readFullAt(r, rest, recordsAddr+uint64(tableBytesRead)); err != nil {
+				return err
+			}
+			skip, ok := resyncOffset(rest)
+			if !ok {
+				warn(fmt.Errorf("%w, no further record found", err))
+				return nil
 			}
-			index++
-		} else {
-			if _, err := f.Seek(int64(HeaderInfo.Length-EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE), io.SeekCurrent); err != nil {
-				return index, nil, err
+			warn(fmt.Errorf("%w, skipped %d bytes to the next record", err, skip))
+			tableBytesRead += uint32(skip)
+			header = buf[:EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE]
+			copy(header, rest[skip:])
+			continue
+		}
+
+		payloadLength := int(HeaderInfo.Length) - EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE
+		readLength := payloadLength
+		if tableBytesRead+uint32(HeaderInfo.Length)+EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE <= recordsLength {
+			readLength += EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE
+		}
+		payloadAddr := recordsAddr + uint64(tableBytesRead) + EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE
+		if err := readFullAt(r, buf[:readLength], payloadAddr); err != nil {
+			return err
+		}
+
+		if err := fn(tableBytesRead, HeaderInfo, buf[:payloadLength]); err != nil {
+			if err == ErrStopWalk {
+				return nil
 			}
+			return err
 		}
+		header = buf[payloadLength:readLength]
 		tableBytesRead += uint32(HeaderInfo.Length)
 	}
+	return nil
+}
+
+// Scanner reads the records of an FBPT. The zero value is ready to use.
+type Scanner struct {
+	// Logger receives diagnostics about skipped and unknown records. The
+	// scanner is silent if it is nil.
+	Logger *log.Logger
+
+	// Progress, if not nil, is called periodically while the records are
+	// read with the number of table bytes read so far and the table length
+	// from the FBPT header, and once more when the scan is complete.
+	Progress func(bytesRead, totalBytes uint32)
 
-	return index, measurementRecords, nil
+	// RelaxedSignature accepts FBPTs whose signature is padded with white
+	// space or NULs or not upper case, as written by some non-conformant
+	// firmware, with a warning.
+	RelaxedSignature bool
+
+	// Strict makes FindAllFBPTRecords fail instead of warn if the FBPT
+	// address is not within a region that /proc/iomem labels as ACPI or
+	// Reserved. A /proc/iomem that cannot be read is only ever a warning.
+	Strict bool
 }
 
-func readFirmwarePerformanceDataTableDynamicRecord(mem io.ReadSeeker, recordLength uint8) (MEASUREMENT_RECORD, error) {
-	var measurementRecord MEASUREMENT_RECORD
-	var HookType [2]byte
-	if _, err := io.ReadFull(mem, HookType[:]); err != nil {
-		return measurementRecord, err
+// progressInterval is the minimum number of table bytes between two calls
+// to Scanner.Progress.
+const progressInterval = 4096
+
+func (s *Scanner) logf(format string, v ...any) {
+	if s.Logger != nil {
+		s.Logger.Printf(format, v...)
 	}
+}
+
+// FindAllFBPTRecords returns the dynamic string event records of the FBPT at
+// FBPTAddr using a default Scanner.
+func FindAllFBPTRecords(FBPTAddr uint64) ([]MEASUREMENT_RECORD, []error, error) {
+	var s Scanner
+	return s.FindAllFBPTRecords(FBPTAddr)
+}
 
-	var ProcessorIdentifier [4]byte
-	if _, err := io.ReadFull(mem, ProcessorIdentifier[:]); err != nil {
-		return measurementRecord, err
+// FindAllFBPTRecords returns the dynamic string event records of the FBPT at
+// FBPTAddr. Records that cannot be decoded are skipped and reported as
+// warnings; err is only set when the table as a whole cannot be read. An
+// FBPTAddr outside of the ACPI and Reserved regions of /proc/iomem is
+// reported as warning too, or as error if s.Strict is set, before /dev/mem is
+// read.
+func (s *Scanner) FindAllFBPTRecords(FBPTAddr uint64) ([]MEASUREMENT_RECORD, []error, error) {
+	var regionWarning error
+	if ok, err := addrInACPIRegion(FBPTAddr); err != nil {
+		regionWarning = fmt.Errorf("can't check FBPT address %#x against the ACPI regions: %w", FBPTAddr, err)
+	} else if !ok {
+		regionWarning = fmt.Errorf("FBPT address %#x is outside of the ACPI and Reserved regions of %s", FBPTAddr, iomemPath)
+		if s.Strict {
+			return nil, nil, regionWarning
+		}
+	}
+	if regionWarning != nil {
+		s.logf("fbpt: %v", regionWarning)
 	}
 
-	var Timestamp [8]byte
-	if _, err := io.ReadFull(mem, Timestamp[:]); err != nil {
-		return measurementRecord, err
+	var f *os.File
+	var err error
+	if f, err = os.OpenFile(memDevice, os.O_RDONLY, 0); err != nil {
+		return nil, nil, err
 	}
+	defer f.Close()
 
-	var Guid [16]byte
-	if _, err := io.ReadFull(mem, Guid[:]); err != nil {
-		return measurementRecord, err
+	records, warnings, err := s.findAllFBPTRecords(f, FBPTAddr)
+	if regionWarning != nil {
+		warnings = append([]error{regionWarning}, warnings...)
+	}
+	return records, warnings, err
+}
+
+// ReadFBPTRecords is like FindAllFBPTRecords but reads the FBPT from mem, which
+// is addressed by physical address. Callers scanning the table repeatedly can
+// keep /dev/mem open and pass it here.
+func (s *Scanner) ReadFBPTRecords(mem io.ReaderAt, FBPTAddr uint64) ([]MEASUREMENT_RECORD, []error, error) {
+	return s.findAllFBPTRecords(mem, FBPTAddr)
+}
+
+func (s *Scanner) findAllFBPTRecords(mem io.ReaderAt, FBPTAddr uint64) ([]MEASUREMENT_RECORD, []error, error) {
+	var measurementRecords []MEASUREMENT_RECORD
+	var warnings []error
+	warn := func(err error) {
+		s.logf("fbpt: %v", err)
+		warnings = append(warnings, err)
+	}
+
+	var relaxed func(error)
+	if s.RelaxedSignature {
+		relaxed = warn
+	}
+	tableLength, err := verifyFBPTSignatureRelaxed(mem, FBPTAddr, relaxed)
+	if err != nil {
+		return nil, nil, err
+	}
+	var reported uint32
+	if s.Progress != nil {
+		s.Progress(0, tableLength)
+	}
+	progress := func(bytesRead uint32) {
+		if s.Progress != nil && bytesRead != reported {
+			reported = bytesRead
+			s.Progress(bytesRead, tableLength)
+		}
 	}
 
-	String := make([]byte, recordLength-34)
-	if _, err := io.ReadFull(mem, String[:]); err != nil {
-		return measurementRecord, err
+	err = walk(mem, FBPTAddr, tableLength, func(offset uint32, HeaderInfo EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, payload []byte) error {
+		if bytesRead := EFI_ACPI_5_0_FBPT_HEADER_SIZE + offset + uint32(HeaderInfo.Length); bytesRead-reported >= progressInterval {
+			progress(bytesRead)
+		}
+		if HeaderInfo.Type != FPDT_DYNAMIC_STRING_EVENT_RECORD_IDENTIFIER {
+			s.logf("fbpt: skipping record of type %#x at table offset %d", HeaderInfo.Type, offset)
+			return nil
+		}
+		if HeaderInfo.Revision != FPDT_DYNAMIC_STRING_EVENT_RECORD_REVISION {
+			warn(fmt.Errorf("record at table offset %d: unknown dynamic string event record revision %d", offset, HeaderInfo.Revision))
+			return nil
+		}
+		record, err := parseDynamicRecord(payload)
+		if err != nil {
+			warn(fmt.Errorf("record at table offset %d: %w", offset, err))
+			return nil
+		}
+		if record.HookType == "" {
+			s.logf("fbpt: unknown hook type %#x at table offset %d", record.HookID, offset)
+		}
+		measurementRecords = append(measurementRecords, record)
+		if len(measurementRecords) == maxNumberOfFBPTPerfRecords {
+			return ErrStopWalk
+		}
+		return nil
+	}, warn)
+	if err == nil && len(measurementRecords) < maxNumberOfFBPTPerfRecords {
+		progress(tableLength)
 	}
+	return measurementRecords, warnings, err
+}
+
+// splitRecord splits the record at the start of b into its header and its
+// payload. The header is exactly EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE
+// bytes, any reserved fields belong to the type specific payload. A Length
+// shorter than the header or running past the end of b is an error.
+func splitRecord(b []byte) (EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, []byte, error) {
+	if len(b) < EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE {
+		return EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER{}, nil, fmt.Errorf("record truncated to %d bytes", len(b))
+	}
+	hdr := parseRecordHeader(b)
+	if hdr.Length < EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE || int(hdr.Length) > len(b) {
+		return hdr, nil, fmt.Errorf("record has invalid length %d", hdr.Length)
+	}
+	return hdr, b[EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE:hdr.Length], nil
+}
+
+func parseRecordHeader(b []byte) EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER {
+	return EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER{
+		Type:     binary.LittleEndian.Uint16(b[0:2]),
+		Length:   b[2],
+		Revision: b[3],
+	}
+}
+
+// parseDynamicRecord decodes the payload of a dynamic string event record,
+// i.e. everything following the record header.
+func parseDynamicRecord(payload []byte) (MEASUREMENT_RECORD, error) {
+	var measurementRecord MEASUREMENT_RECORD
+	if len(payload) < dynamicRecordFixedSize {
+		return measurementRecord, fmt.Errorf("dynamic string event record too short: %d bytes", len(payload))
+	}
+
+	var Guid [16]byte
+	copy(Guid[:], payload[14:30])
 
-	measurementRecord.HookType = eventTypeMap[binary.LittleEndian.Uint16(HookType[:])]
-	measurementRecord.ProcessorIdentifier = binary.LittleEndian.Uint32(ProcessorIdentifier[:])
-	measurementRecord.Timestamp = binary.LittleEndian.Uint64(Timestamp[:])
+	measurementRecord.HookID = binary.LittleEndian.Uint16(payload[0:2])
+	measurementRecord.HookType = eventTypeMap[measurementRecord.HookID]
+	measurementRecord.ProcessorIdentifier = binary.LittleEndian.Uint32(payload[2:6])
+	measurementRecord.Timestamp = binary.LittleEndian.Uint64(payload[6:14])
 	measurementRecord.GUID = uefivars.MixedGUID(Guid)
-	measurementRecord.Description = string(String[:])
+	measurementRecord.Description = string(payload[dynamicRecordFixedSize:])
 
 	return measurementRecord, nil
 }
@@ -0,0 +1,526 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/acpi/fbpt/fbpttest"
+	"github.com/u-root/u-root/pkg/acpi/fpdt"
+)
+
+// tableAddr is where the synthetic FBPT is placed within the fake memory.
+const tableAddr = 0x100
+
+// dynamicRecord is fbpttest.DynamicRecord on processor 1 with a zero GUID.
+func dynamicRecord(hookID uint16, timestamp uint64, description string) []byte {
+	return fbpttest.DynamicRecord(hookID, 1, timestamp, [16]byte{}, description)
+}
+
+func otherRecord(recordType uint16, length uint8) []byte {
+	b := make([]byte, length)
+	binary.LittleEndian.PutUint16(b[0:], recordType)
+	b[2] = length
+	b[3] = 2
+	return b
+}
+
+// fakeMem returns memory holding an FBPT made of the given records at tableAddr.
+func fakeMem(records ...[]byte) []byte {
+	return append(make([]byte, tableAddr), fbpttest.FBPT(records...)...)
+}
+
+func TestFindAllFBPTRecords(t *testing.T) {
+	mem := fakeMem(
+		otherRecord(0x0002, 0x30),
+		dynamicRecord(MODULE_START_ID, 100, "PEI"),
+		otherRecord(0x0003, 0x10),
+		dynamicRecord(MODULE_END_ID, 200, ""),
+	)
+	records, warnings, err := (&Scanner{}).findAllFBPTRecords(bytes.NewReader(mem), tableAddr)
+	if err != nil {
+		t.Fatalf("findAllFBPTRecords() = _, _, %v, want nil", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("findAllFBPTRecords() = _, %v, _, want no warnings", warnings)
+	}
+	want := []MEASUREMENT_RECORD{
+		{HookID: MODULE_START_ID, HookType: "MODULE_START_ID", ProcessorIdentifier: 1, Timestamp: 100, Description: "PEI"},
+		{HookID: MODULE_END_ID, HookType: "MODULE_END_ID", ProcessorIdentifier: 1, Timestamp: 200},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("findAllFBPTRecords() = %+v, _, _, want %+v", records, want)
+	}
+}
+
+func TestFPDTToFBPT(t *testing.T) {
+	guid := [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	tables, err := fbpttest.Build(
+		fbpttest.BasicBootRecord(1, 2, 3, 4, 5),
+		fbpttest.DynamicRecord(MODULE_START_ID, 3, 100, guid, "PEI"),
+	)
+	if err != nil {
+		t.Fatalf("fbpttest.Build() = _, %v, want nil", err)
+	}
+	addr, err := fpdt.FindFBPTTableAdrr(tables.FPDT)
+	if err != nil || addr != tables.FBPTAddr {
+		t.Fatalf("fpdt.FindFBPTTableAdrr() = %#x, %v, want %#x, nil", addr, err, tables.FBPTAddr)
+	}
+
+	records, warnings, err := (&Scanner{}).findAllFBPTRecords(tables.Mem, addr)
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("findAllFBPTRecords() = _, %v, %v, want no warnings and nil", warnings, err)
+	}
+	want := []MEASUREMENT_RECORD{
+		{HookID: MODULE_START_ID, HookType: "MODULE_START_ID", ProcessorIdentifier: 3, Timestamp: 100, GUID: guid, Description: "PEI"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("findAllFBPTRecords() = %+v, _, _, want %+v", records, want)
+	}
+	boot, err := readBasicBootRecord(tables.Mem, addr)
+	if err != nil || boot.ExitBootServicesExit != 5 {
+		t.Errorf("readBasicBootRecord() = %+v, %v, want ExitBootServicesExit 5", boot, err)
+	}
+}
+
+func TestFindAllFBPTRecordsWarnings(t *testing.T) {
+	unknownRevision := dynamicRecord(MODULE_START_ID, 100, "rev 7")
+	unknownRevision[3] = 7
+	short := otherRecord(FPDT_DYNAMIC_STRING_EVENT_RECORD_IDENTIFIER, 0x10)
+	short[3] = FPDT_DYNAMIC_STRING_EVENT_RECORD_REVISION
+
+	mem := fakeMem(
+		dynamicRecord(MODULE_START_ID, 100, "PEI"),
+		unknownRevision,
+		short,
+		dynamicRecord(MODULE_END_ID, 200, "PEI"),
+	)
+	records, warnings, err := (&Scanner{}).findAllFBPTRecords(bytes.NewReader(mem), tableAddr)
+	if err != nil {
+		t.Fatalf("findAllFBPTRecords() = _, _, %v, want nil", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("findAllFBPTRecords() returned %d records, want 2", len(records))
+	}
+	if len(warnings) != 2 {
+		t.Errorf("findAllFBPTRecords() = _, %v, _, want 2 warnings", warnings)
+	}
+}
+
+func TestFindAllFBPTRecordsResync(t *testing.T) {
+	wrongLength := dynamicRecord(MODULE_START_ID, 100, "PEI")
+	// Make the next header land in the middle of the following record.
+	wrongLength[2] += 10
+	mem := fakeMem(
+		wrongLength,
+		dynamicRecord(MODULE_END_ID, 200, "PEI"),
+		dynamicRecord(MODULE_START_ID, 300, "DXE"),
+		otherRecord(0x0003, 0x10),
+		dynamicRecord(MODULE_END_ID, 400, "DXE"),
+	)
+
+	if err := Walk(bytes.NewReader(mem), tableAddr, func(EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, []byte) error { return nil }); err == nil {
+		t.Errorf("Walk() = nil, want invalid length error")
+	}
+
+	records, warnings, err := (&Scanner{}).findAllFBPTRecords(bytes.NewReader(mem), tableAddr)
+	if err != nil {
+		t.Fatalf("findAllFBPTRecords() = _, _, %v, want nil", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("findAllFBPTRecords() = _, %v, _, want 1 warning", warnings)
+	}
+	var timestamps []uint64
+	for _, r := range records {
+		timestamps = append(timestamps, r.Timestamp)
+	}
+	// Only the record overlapped by the wrong length is lost.
+	if want := []uint64{100, 300, 400}; !reflect.DeepEqual(timestamps, want) {
+		t.Errorf("findAllFBPTRecords() returned records with timestamps %d, want %d", timestamps, want)
+	}
+}
+
+func TestFindAllFBPTRecordsFinalRecordOverrun(t *testing.T) {
+	overrun := dynamicRecord(MODULE_END_ID, 200, "PEI")
+	// The table ends 4 bytes before the declared end of the last record.
+	overrun[2] += 4
+	mem := fakeMem(
+		dynamicRecord(MODULE_START_ID, 100, "PEI"),
+		overrun,
+	)
+
+	records, warnings, err := (&Scanner{}).findAllFBPTRecords(bytes.NewReader(mem), tableAddr)
+	if err != nil {
+		t.Fatalf("findAllFBPTRecords() = _, _, %v, want nil", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "overrunning the table by 4 bytes") {
+		t.Errorf("findAllFBPTRecords() = _, %v, _, want 1 overrun warning", warnings)
+	}
+	want := []MEASUREMENT_RECORD{
+		{HookID: MODULE_START_ID, HookType: "MODULE_START_ID", ProcessorIdentifier: 1, Timestamp: 100, Description: "PEI"},
+		{HookID: MODULE_END_ID, HookType: "MODULE_END_ID", ProcessorIdentifier: 1, Timestamp: 200, Description: "PEI"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("findAllFBPTRecords() = %+v, _, _, want %+v", records, want)
+	}
+
+	if err := Walk(bytes.NewReader(mem), tableAddr, func(EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, []byte) error { return nil }); err == nil {
+		t.Errorf("Walk() = nil, want invalid length error")
+	}
+}
+
+func TestFindAllFBPTRecordsTruncatedFinalHeader(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		records [][]byte
+		want    int
+	}{
+		{
+			name:    "after records",
+			records: [][]byte{dynamicRecord(MODULE_START_ID, 100, "PEI"), dynamicRecord(MODULE_END_ID, 200, "PEI")[:2]},
+			want:    1,
+		},
+		{
+			name:    "only record",
+			records: [][]byte{dynamicRecord(MODULE_START_ID, 100, "PEI")[:3]},
+			want:    0,
+		},
+	} {
+		// Nothing follows the table, so reading a header past its end fails.
+		mem := fakeMem(tt.records...)
+		records, warnings, err := (&Scanner{}).findAllFBPTRecords(bytes.NewReader(mem), tableAddr)
+		if err != nil {
+			t.Fatalf("findAllFBPTRecords(%s) = _, _, %v, want nil", tt.name, err)
+		}
+		if len(records) != tt.want {
+			t.Errorf("findAllFBPTRecords(%s) returned %d records, want %d", tt.name, len(records), tt.want)
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "too short for a record header") {
+			t.Errorf("findAllFBPTRecords(%s) = _, %v, _, want 1 truncated header warning", tt.name, warnings)
+		}
+	}
+}
+
+func TestScannerLogger(t *testing.T) {
+	unknownRevision := dynamicRecord(MODULE_START_ID, 100, "rev 7")
+	unknownRevision[3] = 7
+	mem := fakeMem(
+		otherRecord(0x0002, 0x30),
+		dynamicRecord(0xbeef, 100, "unknown hook"),
+		unknownRevision,
+	)
+
+	var logs bytes.Buffer
+	s := Scanner{Logger: log.New(&logs, "", 0)}
+	if _, _, err := s.findAllFBPTRecords(bytes.NewReader(mem), tableAddr); err != nil {
+		t.Fatalf("findAllFBPTRecords() = _, _, %v, want nil", err)
+	}
+	for _, want := range []string{
+		"skipping record of type 0x2 at table offset 0",
+		"unknown hook type 0xbeef at table offset 48",
+		"unknown dynamic string event record revision 7",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("Scanner logged %q, want it to contain %q", logs.String(), want)
+		}
+	}
+}
+
+func TestScannerProgress(t *testing.T) {
+	var records [][]byte
+	for i := 0; i < 200; i++ {
+		records = append(records, dynamicRecord(PERF_INMODULE_START_ID, uint64(i), fmt.Sprintf("record %d", i)))
+	}
+	mem := fakeMem(records...)
+	total := uint32(len(mem) - tableAddr)
+
+	var reads []uint32
+	s := Scanner{Progress: func(bytesRead, totalBytes uint32) {
+		if totalBytes != total {
+			t.Errorf("Progress() called with totalBytes %d, want %d", totalBytes, total)
+		}
+		reads = append(reads, bytesRead)
+	}}
+	if _, _, err := s.findAllFBPTRecords(bytes.NewReader(mem), tableAddr); err != nil {
+		t.Fatalf("findAllFBPTRecords() = _, _, %v, want nil", err)
+	}
+	if len(reads) < 3 {
+		t.Fatalf("Progress() called with %d, want periodic calls", reads)
+	}
+	if reads[0] != 0 || reads[len(reads)-1] != total {
+		t.Errorf("Progress() called with %d, want first 0 and last %d", reads, total)
+	}
+	for i := 1; i < len(reads); i++ {
+		if reads[i] <= reads[i-1] {
+			t.Errorf("Progress() called with %d, want increasing bytesRead", reads)
+			break
+		}
+	}
+}
+
+func TestFindAllFBPTRecordsBadSignature(t *testing.T) {
+	mem := fakeMem(dynamicRecord(MODULE_START_ID, 100, "PEI"))
+	copy(mem[tableAddr:], "XXXX")
+	if _, _, err := (&Scanner{}).findAllFBPTRecords(bytes.NewReader(mem), tableAddr); err == nil {
+		t.Errorf("findAllFBPTRecords() = _, _, nil, want error")
+	}
+}
+
+func TestScannerRelaxedSignature(t *testing.T) {
+	for _, tt := range []struct {
+		sig       string
+		relaxedOK bool
+	}{
+		{sig: "fbpt", relaxedOK: true},
+		{sig: "Fbpt", relaxedOK: true},
+		{sig: "FBP\x00", relaxedOK: false},
+	} {
+		mem := fakeMem(dynamicRecord(MODULE_START_ID, 100, "PEI"))
+		copy(mem[tableAddr:], tt.sig)
+		if _, _, err := (&Scanner{}).findAllFBPTRecords(bytes.NewReader(mem), tableAddr); err == nil {
+			t.Errorf("findAllFBPTRecords() with signature %q = _, _, nil, want error", tt.sig)
+		}
+		records, warnings, err := (&Scanner{RelaxedSignature: true}).findAllFBPTRecords(bytes.NewReader(mem), tableAddr)
+		if !tt.relaxedOK {
+			if err == nil {
+				t.Errorf("relaxed findAllFBPTRecords() with signature %q = _, _, nil, want error", tt.sig)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("relaxed findAllFBPTRecords() with signature %q = _, _, %v, want nil", tt.sig, err)
+		}
+		if len(records) != 1 || len(warnings) != 1 {
+			t.Errorf("relaxed findAllFBPTRecords() with signature %q = %v, %v, _, want 1 record and 1 warning", tt.sig, records, warnings)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	mem := fakeMem(
+		otherRecord(0x0002, 0x30),
+		dynamicRecord(MODULE_START_ID, 100, "PEI"),
+		otherRecord(0x0003, 0x10),
+	)
+
+	var types []uint16
+	var lengths []int
+	err := Walk(bytes.NewReader(mem), tableAddr, func(hdr EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, payload []byte) error {
+		types = append(types, hdr.Type)
+		lengths = append(lengths, len(payload))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() = %v, want nil", err)
+	}
+	if want := []uint16{0x0002, FPDT_DYNAMIC_STRING_EVENT_RECORD_IDENTIFIER, 0x0003}; !reflect.DeepEqual(types, want) {
+		t.Errorf("Walk() visited types %#x, want %#x", types, want)
+	}
+	if want := []int{0x2c, dynamicRecordFixedSize + 3, 0x0c}; !reflect.DeepEqual(lengths, want) {
+		t.Errorf("Walk() visited payload lengths %d, want %d", lengths, want)
+	}
+
+	var visited int
+	err = Walk(bytes.NewReader(mem), tableAddr, func(EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, []byte) error {
+		visited++
+		return ErrStopWalk
+	})
+	if err != nil || visited != 1 {
+		t.Errorf("Walk() with ErrStopWalk = %v after %d records, want nil after 1", err, visited)
+	}
+
+	errFn := errors.New("callback error")
+	err = Walk(bytes.NewReader(mem), tableAddr, func(EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, []byte) error {
+		return errFn
+	})
+	if err != errFn {
+		t.Errorf("Walk() = %v, want %v", err, errFn)
+	}
+}
+
+func TestDumpTable(t *testing.T) {
+	mem := fakeMem(
+		otherRecord(0x0002, 0x30),
+		dynamicRecord(MODULE_START_ID, 100, "PEI"),
+	)
+	// Memory following the table must not be part of the dump.
+	mem = append(mem, "trailing garbage"...)
+	table, err := dumpTable(bytes.NewReader(mem), tableAddr)
+	if err != nil {
+		t.Fatalf("dumpTable() = _, %v, want nil", err)
+	}
+	if want := mem[tableAddr : len(mem)-len("trailing garbage")]; !bytes.Equal(table, want) {
+		t.Errorf("dumpTable() = %x, want %x", table, want)
+	}
+
+	copy(mem[tableAddr:], "XXXX")
+	if _, err := dumpTable(bytes.NewReader(mem), tableAddr); err == nil {
+		t.Errorf("dumpTable() with bad signature = _, nil, want error")
+	}
+}
+
+func TestHookCategory(t *testing.T) {
+	for id := range eventTypeMap {
+		if HookCategory(id) == "" {
+			t.Errorf("HookCategory(%#x) = \"\", want a category for %s", id, eventTypeMap[id])
+		}
+	}
+	for _, tt := range []struct {
+		id   uint16
+		want string
+	}{
+		{MODULE_LOADIMAGE_START_ID, "module"},
+		{MODULE_DB_SUPPORT_END_ID, "database"},
+		{PERF_EVENTSIGNAL_START_ID, "callback"},
+		{PERF_FUNCTION_END_ID, "function"},
+		{PERF_CROSSMODULE_START_ID, "module-scope"},
+		{0xbeef, ""},
+	} {
+		if got := HookCategory(tt.id); got != tt.want {
+			t.Errorf("HookCategory(%#x) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+// oneByteReader returns at most one byte per ReadAt call, like /dev/mem can
+// near region boundaries.
+type oneByteReader struct {
+	io.ReaderAt
+}
+
+func (o oneByteReader) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return o.ReaderAt.ReadAt(p, off)
+}
+
+func TestFindAllFBPTRecordsShortReads(t *testing.T) {
+	mem := fakeMem(
+		otherRecord(0x0002, 0x30),
+		dynamicRecord(MODULE_START_ID, 100, "PEI"),
+		dynamicRecord(MODULE_END_ID, 200, ""),
+	)
+	want, _, err := (&Scanner{}).findAllFBPTRecords(bytes.NewReader(mem), tableAddr)
+	if err != nil {
+		t.Fatalf("findAllFBPTRecords() = _, _, %v, want nil", err)
+	}
+	records, _, err := (&Scanner{}).findAllFBPTRecords(oneByteReader{bytes.NewReader(mem)}, tableAddr)
+	if err != nil {
+		t.Fatalf("findAllFBPTRecords() with one byte reads = _, _, %v, want nil", err)
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("findAllFBPTRecords() with one byte reads = %+v, _, _, want %+v", records, want)
+	}
+
+	// Memory ending in the middle of the last record.
+	truncated := mem[:len(mem)-4]
+	_, _, err = (&Scanner{}).findAllFBPTRecords(oneByteReader{bytes.NewReader(truncated)}, tableAddr)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("findAllFBPTRecords() on truncated memory = _, _, %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if offset := fmt.Sprintf("%#x", tableAddr+EFI_ACPI_5_0_FBPT_HEADER_SIZE+0x30+34+3+EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE); !strings.Contains(err.Error(), offset) {
+		t.Errorf("findAllFBPTRecords() on truncated memory = _, _, %v, want offset %s", err, offset)
+	}
+}
+
+// countingReader counts the calls reaching the underlying memory.
+type countingReader struct {
+	io.ReaderAt
+	calls int
+}
+
+func (c *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	c.calls++
+	return c.ReaderAt.ReadAt(p, off)
+}
+
+func BenchmarkFindAllFBPTRecords(b *testing.B) {
+	var records [][]byte
+	for i := 0; i < maxNumberOfFBPTPerfRecords; i++ {
+		if i%4 == 0 {
+			records = append(records, otherRecord(0x0003, 0x10))
+			continue
+		}
+		records = append(records, dynamicRecord(PERF_INMODULE_START_ID, uint64(i), fmt.Sprintf("record %d", i)))
+	}
+	mem := fakeMem(records...)
+
+	b.ResetTimer()
+	var calls int
+	for i := 0; i < b.N; i++ {
+		r := &countingReader{ReaderAt: bytes.NewReader(mem)}
+		if _, _, err := (&Scanner{}).findAllFBPTRecords(r, tableAddr); err != nil {
+			b.Fatal(err)
+		}
+		calls += r.calls
+	}
+	b.ReportMetric(float64(calls)/float64(b.N*len(records)), "calls/record")
+}
+
+func TestRecordLengthBelowHeaderSize(t *testing.T) {
+	for length := uint8(0); length < EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE; length++ {
+		short := otherRecord(0x0003, 0x10)
+		short[2] = length
+		if _, _, err := splitRecord(short); err == nil {
+			t.Errorf("splitRecord(Length %d) = _, _, nil, want error", length)
+		}
+
+		mem := fakeMem(dynamicRecord(MODULE_START_ID, 100, "PEI"), short)
+		if err := Walk(bytes.NewReader(mem), tableAddr, func(EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, []byte) error { return nil }); err == nil {
+			t.Errorf("Walk(Length %d) = nil, want invalid length error", length)
+		}
+		if _, err := readAllRecords(bytes.NewReader(mem), tableAddr); err == nil {
+			t.Errorf("readAllRecords(Length %d) = _, nil, want error", length)
+		}
+		if _, err := CountRecordsByType(bytes.NewReader(mem), tableAddr); err == nil {
+			t.Errorf("CountRecordsByType(Length %d) = _, nil, want error", length)
+		}
+	}
+
+	hdr, payload, err := splitRecord(otherRecord(0x0003, 0x10))
+	if err != nil {
+		t.Fatalf("splitRecord() = _, _, %v, want nil", err)
+	}
+	if hdr.Length != 0x10 || len(payload) != 0x10-EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE {
+		t.Errorf("splitRecord() = %+v, %d bytes, _, want Length 16 and 12 bytes", hdr, len(payload))
+	}
+}
+
+func TestTrailingBytesShorterThanHeader(t *testing.T) {
+	for trailing := 1; trailing < EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE; trailing++ {
+		mem := fakeMem(
+			dynamicRecord(MODULE_START_ID, 100, "PEI"),
+			dynamicRecord(MODULE_END_ID, 200, "PEI"),
+			bytes.Repeat([]byte{0xff}, trailing),
+		)
+
+		if err := Walk(bytes.NewReader(mem), tableAddr, func(EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, []byte) error { return nil }); err == nil {
+			t.Errorf("Walk(%d trailing bytes) = nil, want error", trailing)
+		}
+		if _, err := readAllRecords(bytes.NewReader(mem), tableAddr); err == nil {
+			t.Errorf("readAllRecords(%d trailing bytes) = _, nil, want error", trailing)
+		}
+
+		records, warnings, err := (&Scanner{}).ReadFBPTRecords(bytes.NewReader(mem), tableAddr)
+		if err != nil {
+			t.Fatalf("ReadFBPTRecords(%d trailing bytes) = _, _, %v, want nil", trailing, err)
+		}
+		if len(records) != 2 {
+			t.Errorf("ReadFBPTRecords(%d trailing bytes) returned %d records, want 2", trailing, len(records))
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "too short for a record header") {
+			t.Errorf("ReadFBPTRecords(%d trailing bytes) = _, %v, _, want 1 trailing bytes warning", trailing, warnings)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fbpttest builds synthetic FPDT and FBPT tables for tests.
+package fbpttest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/u-root/u-root/pkg/acpi"
+)
+
+const (
+	// FPDTAddr and FBPTAddr are where Build places the tables in memory.
+	FPDTAddr = 0x1000
+	FBPTAddr = 0x2000
+
+	acpiHeaderLength = 36
+)
+
+// Tables is a physical memory image holding an FPDT that points to an FBPT.
+type Tables struct {
+	// Mem is addressed by physical address, like /dev/mem.
+	Mem io.ReaderAt
+	// FPDT is the FPDT table at FPDTAddr.
+	FPDT acpi.Table
+	// FBPTAddr is the address of the FBPT.
+	FBPTAddr uint64
+}
+
+// maxRecordLength is the largest length the one byte length field of a
+// record header can hold.
+const maxRecordLength = 0xff
+
+// record returns a zeroed record of length bytes with its header filled in.
+// It panics if length does not fit the header.
+func record(recordType uint16, revision uint8, length int) []byte {
+	if length > maxRecordLength {
+		panic(fmt.Sprintf("fbpttest: record type %#x of %d bytes exceeds the maximum record length of %d bytes", recordType, length, maxRecordLength))
+	}
+	b := make([]byte, length)
+	binary.LittleEndian.PutUint16(b[0:], recordType)
+	b[2] = uint8(length)
+	b[3] = revision
+	return b
+}
+
+// DynamicRecord returns a dynamic string event record (type 0x1011). It
+// panics if description is too long for the record to fit its one byte
+// length field, i.e. longer than 221 bytes.
+func DynamicRecord(hookID uint16, processor uint32, timestamp uint64, guid [16]byte, description string) []byte {
+	b := record(0x1011, 1, 34+len(description))
+	binary.LittleEndian.PutUint16(b[4:], hookID)
+	binary.LittleEndian.PutUint32(b[6:], processor)
+	binary.LittleEndian.PutUint64(b[10:], timestamp)
+	copy(b[18:], guid[:])
+	copy(b[34:], description)
+	return b
+}
+
+// BasicBootRecord returns a firmware basic boot record (type 0x0002).
+func BasicBootRecord(resetEnd, loadImageStart, startImageStart, exitBootServicesEntry, exitBootServicesExit uint64) []byte {
+	b := record(0x0002, 2, 48)
+	for i, ts := range []uint64{resetEnd, loadImageStart, startImageStart, exitBootServicesEntry, exitBootServicesExit} {
+		binary.LittleEndian.PutUint64(b[8+8*i:], ts)
+	}
+	return b
+}
+
+// FBPT returns an FBPT made of records, with its length field covering the
+// header and all records.
+func FBPT(records ...[]byte) []byte {
+	var fbpt bytes.Buffer
+	fbpt.WriteString("FBPT")
+	length := uint32(8)
+	for _, r := range records {
+		length += uint32(len(r))
+	}
+	binary.Write(&fbpt, binary.LittleEndian, length)
+	for _, r := range records {
+		fbpt.Write(r)
+	}
+	return fbpt.Bytes()
+}
+
+// Build returns memory holding an FPDT at FPDTAddr whose basic boot
+// performance pointer record points to an FBPT at FBPTAddr made of records.
+func Build(records ...[]byte) (*Tables, error) {
+	// FBPT pointer record: type 0, length 16, revision 1, reserved, address.
+	var pointer [16]byte
+	pointer[2] = 16
+	pointer[3] = 1
+	binary.LittleEndian.PutUint64(pointer[8:], FBPTAddr)
+
+	fpdt := make([]byte, acpiHeaderLength, acpiHeaderLength+len(pointer))
+	copy(fpdt, "FPDT")
+	fpdt = append(fpdt, pointer[:]...)
+	binary.LittleEndian.PutUint32(fpdt[4:], uint32(len(fpdt)))
+	fpdt[8] = 1
+	copy(fpdt[10:], "UROOT ")
+	var sum uint8
+	for _, b := range fpdt {
+		sum += b
+	}
+	fpdt[9] = -sum
+
+	fbpt := FBPT(records...)
+	mem := make([]byte, FBPTAddr+len(fbpt))
+	copy(mem[FPDTAddr:], fpdt)
+	copy(mem[FBPTAddr:], fbpt)
+
+	tables, err := acpi.NewRaw(fpdt)
+	if err != nil {
+		return nil, err
+	}
+	return &Tables{Mem: bytes.NewReader(mem), FPDT: tables[0], FBPTAddr: FBPTAddr}, nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import "github.com/u-root/u-root/pkg/uefivars"
+
+// FirmwareVolumeIndex maps the file GUIDs of the drivers in a firmware volume
+// to their names, e.g. as parsed from the file metadata of an FV dump.
+type FirmwareVolumeIndex map[uefivars.MixedGUID]string
+
+// AnnotateWithFirmwareVolume returns a copy of records with the DriverName of
+// every record whose GUID is in fv filled in. Records with a GUID not in fv
+// are copied unchanged.
+func AnnotateWithFirmwareVolume(records []MEASUREMENT_RECORD, fv FirmwareVolumeIndex) []MEASUREMENT_RECORD {
+	annotated := make([]MEASUREMENT_RECORD, len(records))
+	for i, record := range records {
+		if name, ok := fv[record.GUID]; ok {
+			record.DriverName = name
+		}
+		annotated[i] = record
+	}
+	return annotated
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"testing"
+
+	"github.com/u-root/u-root/pkg/uefivars"
+)
+
+func TestAnnotateWithFirmwareVolume(t *testing.T) {
+	pciBus := uefivars.MixedGUID{0x93, 0xb8, 0x20, 0x93}
+	unknown := uefivars.MixedGUID{0x01}
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 10, ""),
+		record(MODULE_START_ID, 20, ""),
+	}
+	records[0].GUID = pciBus
+	records[1].GUID = unknown
+
+	annotated := AnnotateWithFirmwareVolume(records, FirmwareVolumeIndex{pciBus: "PciBusDxe"})
+	if annotated[0].DriverName != "PciBusDxe" {
+		t.Errorf("annotated[0].DriverName = %q, want %q", annotated[0].DriverName, "PciBusDxe")
+	}
+	if annotated[1].DriverName != "" {
+		t.Errorf("annotated[1].DriverName = %q, want \"\"", annotated[1].DriverName)
+	}
+	if records[0].DriverName != "" {
+		t.Errorf("AnnotateWithFirmwareVolume() modified its input")
+	}
+	if got := (PhasePair{Start: annotated[0]}).Name(); got != "PciBusDxe" {
+		t.Errorf("PhasePair.Name() = %q, want %q", got, "PciBusDxe")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"html/template"
+	"io"
+	"time"
+)
+
+const (
+	// htmlChartWidth is the width in pixels of the bars area of the chart
+	// written by WriteHTML.
+	htmlChartWidth = 800
+	// htmlLabelWidth is the width in pixels of the label column.
+	htmlLabelWidth = 240
+	// htmlRowHeight is the height in pixels of the row of every phase.
+	htmlRowHeight = 20
+)
+
+type htmlBar struct {
+	Name     string
+	Start    time.Duration
+	Duration time.Duration
+	Y        int
+	X, Width float64
+}
+
+type htmlReport struct {
+	Total      time.Duration
+	Bars       []htmlBar
+	Width      int
+	Height     int
+	BarHeight  int
+	TextOffset int
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Firmware boot performance</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+svg text { font-size: 12px; }
+rect.bar { fill: #4878c0; }
+rect.bar:hover { fill: #c04848; }
+table { border-collapse: collapse; margin-top: 2em; }
+th, td { padding: 2px 1em; text-align: left; }
+td.num { text-align: right; font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>Firmware boot performance</h1>
+<p>{{len .Bars}} phases over {{.Total}}.</p>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}">
+{{- range .Bars}}
+<text x="0" y="{{.Y}}" dy="{{$.TextOffset}}">{{.Name}}</text>
+<rect class="bar" x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{$.BarHeight}}"><title>{{.Name}}: {{.Duration}} at {{.Start}}</title></rect>
+{{- end}}
+</svg>
+<table>
+<tr><th>Phase</th><th>Start</th><th>Duration</th></tr>
+{{- range .Bars}}
+<tr><td>{{.Name}}</td><td class="num">{{.Start}}</td><td class="num">{{.Duration}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// WriteHTML writes a self-contained HTML page with a gantt chart of the
+// phases found in records, as paired by PairPhases, drawn as inline SVG,
+// followed by a table of the phases. Like with WriteASCIIChart the bars are
+// positioned relative to the start of the first phase. The page needs no
+// external scripts or style sheets, so it can be shared and opened offline.
+func WriteHTML(w io.Writer, records []MEASUREMENT_RECORD) error {
+	phases := PairPhases(records)
+	report := htmlReport{
+		Width:      htmlLabelWidth + htmlChartWidth,
+		Height:     len(phases) * htmlRowHeight,
+		BarHeight:  htmlRowHeight - 4,
+		TextOffset: htmlRowHeight - 6,
+	}
+	if len(phases) > 0 {
+		first, last := phases[0].Start.Timestamp, phases[0].End.Timestamp
+		for _, phase := range phases {
+			if phase.Start.Timestamp < first {
+				first = phase.Start.Timestamp
+			}
+			if phase.End.Timestamp > last {
+				last = phase.End.Timestamp
+			}
+		}
+		report.Total = time.Duration(last - first)
+		scale := 0.0
+		if last > first {
+			scale = htmlChartWidth / float64(last-first)
+		}
+		for i, phase := range phases {
+			start := time.Duration(phase.Start.Timestamp - first)
+			bar := htmlBar{
+				Name:     phase.Name(),
+				Start:    start,
+				Duration: phase.Duration(),
+				Y:        i * htmlRowHeight,
+				X:        htmlLabelWidth + float64(start)*scale,
+				Width:    float64(phase.Duration()) * scale,
+			}
+			// Keep short phases visible.
+			if bar.Width < 1 {
+				bar.Width = 1
+			}
+			report.Bars = append(report.Bars, bar)
+		}
+	}
+	return htmlTemplate.Execute(w, report)
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 1000, "PEI"),
+		record(MODULE_END_ID, 1200, "PEI"),
+		record(PERF_FUNCTION_START_ID, 1200, "<script>alert(1)</script>"),
+		record(PERF_FUNCTION_END_ID, 1200, "<script>alert(1)</script>"),
+		record(MODULE_START_ID, 1400, "DXE"),
+		record(MODULE_END_ID, 2000, "DXE"),
+	}
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, records); err != nil {
+		t.Fatalf("WriteHTML() = %v, want nil", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"<p>3 phases over 1µs.</p>",
+		`<svg xmlns="http://www.w3.org/2000/svg" width="1040" height="60">`,
+		// PEI takes the first fifth of the chart.
+		`<rect class="bar" x="240" y="0" width="160" height="16"><title>PEI: 200ns at 0s</title></rect>`,
+		// The empty phase still gets a bar.
+		`<rect class="bar" x="400" y="20" width="1" height="16">`,
+		`<rect class="bar" x="560" y="40" width="480" height="16"><title>DXE: 600ns at 400ns</title></rect>`,
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteHTML() = %s, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "<script>") || strings.Contains(got, "src=") || strings.Contains(got, "href=") {
+		t.Errorf("WriteHTML() = %s, want no scripts or external resources", got)
+	}
+
+	buf.Reset()
+	if err := WriteHTML(&buf, nil); err != nil || !strings.Contains(buf.String(), "0 phases") {
+		t.Errorf("WriteHTML(nil) = %v, wrote %s, want nil and an empty report", err, buf.String())
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// iomemPath is the kernel's map of the physical address space.
+const iomemPath = "/proc/iomem"
+
+// addrInACPIRegion reports whether addr lies within a region that
+// /proc/iomem labels as ACPI, i.e. "ACPI Tables" or "ACPI Non-volatile
+// Storage", or as "Reserved". The latter is where EDK2 puts the FBPT, which
+// it allocates as EfiReservedMemoryType.
+func addrInACPIRegion(addr uint64) (bool, error) {
+	f, err := os.Open(iomemPath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	return addrInACPIRegionOf(f, addr)
+}
+
+// addrInACPIRegionOf is addrInACPIRegion for the /proc/iomem contents r.
+// Lines that cannot be parsed are ignored.
+func addrInACPIRegionOf(r io.Reader, addr uint64) (bool, error) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		addrRange, name, ok := strings.Cut(strings.TrimSpace(s.Text()), " : ")
+		if !ok || !(strings.Contains(name, "ACPI") || name == "Reserved") {
+			continue
+		}
+		startStr, endStr, ok := strings.Cut(addrRange, "-")
+		if !ok {
+			continue
+		}
+		start, err := strconv.ParseUint(startStr, 16, 64)
+		if err != nil {
+			continue
+		}
+		end, err := strconv.ParseUint(endStr, 16, 64)
+		if err != nil {
+			continue
+		}
+		if start <= addr && addr <= end {
+			return true, nil
+		}
+	}
+	return false, s.Err()
+}
@@ -0,0 +1,38 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddrInACPIRegion(t *testing.T) {
+	const iomem = `00000000-00000fff : Reserved
+00001000-0009ffff : System RAM
+7a000000-7a0fffff : Reserved
+7a100000-7a17ffff : ACPI Tables
+7a180000-7a1fffff : ACPI Non-volatile Storage
+  7a1c0000-7a1c0fff : pnp 00:01
+e0000000-efffffff : PCI MMCONFIG 0000 [bus 00-ff]
+`
+	for _, tt := range []struct {
+		addr uint64
+		want bool
+	}{
+		{addr: 0x7a100000, want: true},
+		{addr: 0x7a17ffff, want: true},
+		{addr: 0x7a1c0100, want: true},
+		{addr: 0x7a000000, want: true},
+		{addr: 0x7a0fffff, want: true},
+		{addr: 0x2000, want: false},
+		{addr: 0xf0000000, want: false},
+	} {
+		got, err := addrInACPIRegionOf(strings.NewReader(iomem), tt.addr)
+		if err != nil || got != tt.want {
+			t.Errorf("addrInACPIRegionOf(%#x) = %t, %v, want %t, nil", tt.addr, got, err, tt.want)
+		}
+	}
+}
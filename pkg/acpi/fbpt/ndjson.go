@@ -0,0 +1,43 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonRecord is the JSON representation of a MEASUREMENT_RECORD.
+type jsonRecord struct {
+	HookID              uint16 `json:"hookID"`
+	HookType            string `json:"hookType"`
+	ProcessorIdentifier uint32 `json:"processorIdentifier"`
+	Timestamp           uint64 `json:"timestamp"`
+	GUID                string `json:"guid"`
+	Description         string `json:"description"`
+	DriverName          string `json:"driverName,omitempty"`
+	Phase               string `json:"phase,omitempty"`
+}
+
+// WriteNDJSON writes records as newline delimited JSON, one object per line,
+// as expected by most log pipelines.
+func WriteNDJSON(w io.Writer, records []MEASUREMENT_RECORD) error {
+	enc := json.NewEncoder(w)
+	for _, record := range records {
+		if err := enc.Encode(jsonRecord{
+			HookID:              record.HookID,
+			HookType:            record.HookType,
+			ProcessorIdentifier: record.ProcessorIdentifier,
+			Timestamp:           record.Timestamp,
+			GUID:                record.GUID.String(),
+			Description:         record.Description,
+			DriverName:          record.DriverName,
+			Phase:               record.Phase,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
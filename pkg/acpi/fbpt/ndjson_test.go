@@ -0,0 +1,44 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 2000, "PEI"),
+		record(MODULE_END_ID, 5000, "multi\nline"),
+	}
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, records); err != nil {
+		t.Fatalf("WriteNDJSON() = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(records) {
+		t.Fatalf("WriteNDJSON() wrote %d lines, want %d:\n%s", len(lines), len(records), buf.String())
+	}
+	for i, line := range lines {
+		var got jsonRecord
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("json.Unmarshal(%q) = %v, want nil", line, err)
+		}
+		want := jsonRecord{
+			HookID:      records[i].HookID,
+			HookType:    records[i].HookType,
+			Timestamp:   records[i].Timestamp,
+			GUID:        records[i].GUID.String(),
+			Description: records[i].Description,
+		}
+		if got != want {
+			t.Errorf("line %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
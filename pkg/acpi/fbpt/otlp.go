@@ -0,0 +1,50 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"fmt"
+	"time"
+)
+
+// Span is an OpenTelemetry span for a boot phase. The JSON field names follow
+// the OTLP JSON encoding, so spans can be embedded into OTLP export requests.
+type Span struct {
+	TraceID string `json:"traceId"`
+	SpanID  string `json:"spanId"`
+	// ParentSpanID is empty for top level phases.
+	ParentSpanID string `json:"parentSpanId,omitempty"`
+	Name         string `json:"name"`
+	// StartTimeUnixNano and EndTimeUnixNano are Unix times in nanoseconds.
+	StartTimeUnixNano uint64 `json:"startTimeUnixNano,string"`
+	EndTimeUnixNano   uint64 `json:"endTimeUnixNano,string"`
+}
+
+// ToOTLPSpans returns a span for every phase found in records, ordered by
+// start time, with the innermost enclosing phase as parent. All spans belong
+// to the trace traceID, their span IDs are derived from their position.
+// Record timestamps are nanoseconds relative to bootEpoch, like for
+// WriteTraceEvent, and converted to the Unix times OTLP expects.
+func ToOTLPSpans(records []MEASUREMENT_RECORD, traceID string, bootEpoch time.Time) []Span {
+	epoch := uint64(bootEpoch.UnixNano())
+	pairs, parents := phaseTree(records)
+	spanID := func(i int) string {
+		return fmt.Sprintf("%016x", i+1)
+	}
+	spans := make([]Span, len(pairs))
+	for i, pair := range pairs {
+		spans[i] = Span{
+			TraceID:           traceID,
+			SpanID:            spanID(i),
+			Name:              pair.Name(),
+			StartTimeUnixNano: epoch + pair.Start.Timestamp,
+			EndTimeUnixNano:   epoch + pair.End.Timestamp,
+		}
+		if parents[i] >= 0 {
+			spans[i].ParentSpanID = spanID(parents[i])
+		}
+	}
+	return spans
+}
@@ -0,0 +1,33 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestToOTLPSpans(t *testing.T) {
+	const traceID = "5b8efff798038103d269b633813fc60c"
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 10, "DXE"),
+		record(PERF_INMODULE_START_ID, 20, "PciBus"),
+		record(PERF_INMODULE_END_ID, 30, "PciBus"),
+		record(MODULE_END_ID, 100, "DXE"),
+		record(MODULE_START_ID, 100, "BDS"),
+		record(MODULE_END_ID, 150, "BDS"),
+	}
+	bootEpoch := time.Unix(1700000000, 0)
+	const epoch = 1700000000 * uint64(time.Second)
+	want := []Span{
+		{TraceID: traceID, SpanID: "0000000000000001", Name: "DXE", StartTimeUnixNano: epoch + 10, EndTimeUnixNano: epoch + 100},
+		{TraceID: traceID, SpanID: "0000000000000002", ParentSpanID: "0000000000000001", Name: "PciBus", StartTimeUnixNano: epoch + 20, EndTimeUnixNano: epoch + 30},
+		{TraceID: traceID, SpanID: "0000000000000003", Name: "BDS", StartTimeUnixNano: epoch + 100, EndTimeUnixNano: epoch + 150},
+	}
+	if got := ToOTLPSpans(records, traceID, bootEpoch); !reflect.DeepEqual(got, want) {
+		t.Errorf("ToOTLPSpans() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"sort"
+	"time"
+
+	"github.com/u-root/u-root/pkg/uefivars"
+)
+
+// phaseEndHooks maps each START hook to the END hook closing it.
+var phaseEndHooks = map[uint16]uint16{
+	MODULE_START_ID:            MODULE_END_ID,
+	MODULE_LOADIMAGE_START_ID:  MODULE_LOADIMAGE_END_ID,
+	MODULE_DB_START_ID:         MODULE_DB_END_ID,
+	MODULE_DB_SUPPORT_START_ID: MODULE_DB_SUPPORT_END_ID,
+	MODULE_DB_STOP_START_ID:    MODULE_DB_STOP_END_ID,
+	PERF_EVENTSIGNAL_START_ID:  PERF_EVENTSIGNAL_END_ID,
+	PERF_CALLBACK_START_ID:     PERF_CALLBACK_END_ID,
+	PERF_FUNCTION_START_ID:     PERF_FUNCTION_END_ID,
+	PERF_INMODULE_START_ID:     PERF_INMODULE_END_ID,
+	PERF_CROSSMODULE_START_ID:  PERF_CROSSMODULE_END_ID,
+}
+
+// IsStart reports whether m is a START hook. EDK2 does not encode this in the
+// hook ID: the MODULE_* hooks (0x01-0x0A) start on odd IDs and end on the
+// following even one, while the PERF_* hooks (0x10-0x51) start on the
+// multiple of 0x10 and end one above it. Unknown hook IDs are neither START
+// nor END hooks.
+func (m MEASUREMENT_RECORD) IsStart() bool {
+	_, ok := phaseEndHooks[m.HookID]
+	return ok
+}
+
+// IsEnd reports whether m is an END hook, see IsStart for the mapping.
+func (m MEASUREMENT_RECORD) IsEnd() bool {
+	for _, end := range phaseEndHooks {
+		if m.HookID == end {
+			return true
+		}
+	}
+	return false
+}
+
+// PhasePair is a START record together with the END record closing it.
+type PhasePair struct {
+	Start MEASUREMENT_RECORD
+	End   MEASUREMENT_RECORD
+}
+
+// Name returns the description of the phase, or its driver name or GUID if
+// there is none.
+func (p PhasePair) Name() string {
+	if p.Start.Description != "" {
+		return p.Start.Description
+	}
+	if p.Start.DriverName != "" {
+		return p.Start.DriverName
+	}
+	return p.Start.GUID.String()
+}
+
+// Duration returns the time spent between the START and END record. It is
+// zero if the END record carries an earlier timestamp.
+func (p PhasePair) Duration() time.Duration {
+	if p.End.Timestamp < p.Start.Timestamp {
+		return 0
+	}
+	return time.Duration(p.End.Timestamp - p.Start.Timestamp)
+}
+
+// Percentage returns the share of total, e.g. the BootDuration of the basic
+// boot record, spent in the phase. It is zero if total is not positive.
+func (p PhasePair) Percentage(total time.Duration) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return 100 * float64(p.Duration()) / float64(total)
+}
+
+type phaseKey struct {
+	endHook uint16
+	guid    uefivars.MixedGUID
+}
+
+// PairPhases matches each START record with the following END record of the
+// same kind and GUID. Nested phases of the same kind are matched innermost
+// first. Records without counterpart are dropped. The pairs are ordered by
+// the position of their START record.
+func PairPhases(records []MEASUREMENT_RECORD) []PhasePair {
+	open := make(map[phaseKey][]int)
+	starts := make(map[int]PhasePair)
+	for i, record := range records {
+		if endHook, ok := phaseEndHooks[record.HookID]; ok {
+			key := phaseKey{endHook: endHook, guid: record.GUID}
+			open[key] = append(open[key], i)
+			continue
+		}
+		if !record.IsEnd() {
+			continue
+		}
+		key := phaseKey{endHook: record.HookID, guid: record.GUID}
+		stack := open[key]
+		if len(stack) == 0 {
+			continue
+		}
+		start := stack[len(stack)-1]
+		open[key] = stack[:len(stack)-1]
+		starts[start] = PhasePair{Start: records[start], End: record}
+	}
+
+	indices := make([]int, 0, len(starts))
+	for i := range starts {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	pairs := make([]PhasePair, 0, len(indices))
+	for _, i := range indices {
+		pairs = append(pairs, starts[i])
+	}
+	return pairs
+}
+
+// AssignPhases returns a copy of records with the Phase of every record set
+// to the name, as returned by PhasePair.Name, of the innermost module
+// enclosing it: the last MODULE_START_ID record before it that is not yet
+// closed by a MODULE_END_ID record with the same GUID. The START and END
+// records of a module belong to the module itself. Records outside of any
+// module keep an empty Phase.
+func AssignPhases(records []MEASUREMENT_RECORD) []MEASUREMENT_RECORD {
+	assigned := make([]MEASUREMENT_RECORD, len(records))
+	var open []MEASUREMENT_RECORD
+	for i, record := range records {
+		if record.HookID == MODULE_START_ID {
+			open = append(open, record)
+		}
+		record.Phase = ""
+		if len(open) > 0 {
+			record.Phase = PhasePair{Start: open[len(open)-1]}.Name()
+		}
+		if record.HookID == MODULE_END_ID {
+			// Modules left open inside the closed one are closed with it.
+			for j := len(open) - 1; j >= 0; j-- {
+				if open[j].GUID == record.GUID {
+					record.Phase = PhasePair{Start: open[j]}.Name()
+					open = open[:j]
+					break
+				}
+			}
+		}
+		assigned[i] = record
+	}
+	return assigned
+}
+
+// contains reports whether phase q lies within p.
+func (p PhasePair) contains(q PhasePair) bool {
+	return p.Start.Timestamp <= q.Start.Timestamp && q.End.Timestamp <= p.End.Timestamp
+}
+
+// phaseTree nests the phases of records by time: a phase is the child of the
+// innermost phase enclosing it. It returns the phases ordered by start time
+// and, for each, the index of its parent or -1.
+func phaseTree(records []MEASUREMENT_RECORD) ([]PhasePair, []int) {
+	pairs := PairPhases(records)
+	// Outer phases go first if phases start at the same time.
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].Start.Timestamp != pairs[j].Start.Timestamp {
+			return pairs[i].Start.Timestamp < pairs[j].Start.Timestamp
+		}
+		return pairs[i].Duration() > pairs[j].Duration()
+	})
+
+	parents := make([]int, len(pairs))
+	var stack []int
+	for i, pair := range pairs {
+		for len(stack) > 0 && !pairs[stack[len(stack)-1]].contains(pair) {
+			stack = stack[:len(stack)-1]
+		}
+		parents[i] = -1
+		if len(stack) > 0 {
+			parents[i] = stack[len(stack)-1]
+		}
+		stack = append(stack, i)
+	}
+	return pairs, parents
+}
+
+// CriticalPath returns the chain of nested phases that dominates the boot
+// time: the longest top level phase, followed by its longest sub-phase, and
+// so on down to a phase without sub-phases. It is where optimizing pays off
+// first.
+func CriticalPath(records []MEASUREMENT_RECORD) []PhasePair {
+	pairs, parents := phaseTree(records)
+
+	var path []PhasePair
+	current := -1
+	for {
+		longest := -1
+		for i, parent := range parents {
+			if parent == current && (longest < 0 || pairs[i].Duration() > pairs[longest].Duration()) {
+				longest = i
+			}
+		}
+		if longest < 0 {
+			return path
+		}
+		path = append(path, pairs[longest])
+		current = longest
+	}
+}
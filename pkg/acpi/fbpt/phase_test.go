@@ -0,0 +1,139 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func record(hookID uint16, timestamp uint64, description string) MEASUREMENT_RECORD {
+	return MEASUREMENT_RECORD{HookID: hookID, HookType: eventTypeMap[hookID], Timestamp: timestamp, Description: description}
+}
+
+func TestPairPhases(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_END_ID, 5, "stray end"),
+		record(MODULE_START_ID, 10, "outer"),
+		record(PERF_INMODULE_START_ID, 20, "inner"),
+		record(MODULE_START_ID, 30, "nested"),
+		record(MODULE_END_ID, 40, "nested"),
+		record(PERF_INMODULE_END_ID, 50, "inner"),
+		record(MODULE_END_ID, 100, "outer"),
+		record(MODULE_START_ID, 110, "unterminated"),
+	}
+	want := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"outer", 90},
+		{"inner", 30},
+		{"nested", 10},
+	}
+
+	pairs := PairPhases(records)
+	if len(pairs) != len(want) {
+		t.Fatalf("PairPhases() = %+v, want %d pairs", pairs, len(want))
+	}
+	for i, w := range want {
+		if pairs[i].Name() != w.name || pairs[i].Duration() != w.duration {
+			t.Errorf("pairs[%d] = %s (%v), want %s (%v)", i, pairs[i].Name(), pairs[i].Duration(), w.name, w.duration)
+		}
+	}
+}
+
+func TestIsStartIsEnd(t *testing.T) {
+	for _, tt := range []struct {
+		hookID     uint16
+		start, end bool
+	}{
+		{MODULE_START_ID, true, false},
+		{MODULE_END_ID, false, true},
+		{MODULE_DB_STOP_START_ID, true, false},
+		{MODULE_DB_STOP_END_ID, false, true},
+		{PERF_EVENTSIGNAL_START_ID, true, false},
+		{PERF_EVENTSIGNAL_END_ID, false, true},
+		{PERF_CROSSMODULE_START_ID, true, false},
+		{PERF_CROSSMODULE_END_ID, false, true},
+		{0x0B, false, false},
+		{0x12, false, false},
+	} {
+		r := MEASUREMENT_RECORD{HookID: tt.hookID}
+		if got := r.IsStart(); got != tt.start {
+			t.Errorf("MEASUREMENT_RECORD{HookID: %#x}.IsStart() = %t, want %t", tt.hookID, got, tt.start)
+		}
+		if got := r.IsEnd(); got != tt.end {
+			t.Errorf("MEASUREMENT_RECORD{HookID: %#x}.IsEnd() = %t, want %t", tt.hookID, got, tt.end)
+		}
+	}
+}
+
+func TestAssignPhases(t *testing.T) {
+	module := func(hookID uint16, guid byte, description string) MEASUREMENT_RECORD {
+		r := record(hookID, 0, description)
+		r.GUID[0] = guid
+		return r
+	}
+	records := []MEASUREMENT_RECORD{
+		record(PERF_FUNCTION_START_ID, 0, "outside"),
+		module(MODULE_START_ID, 1, "DxeCore"),
+		record(PERF_INMODULE_START_ID, 0, "in DxeCore"),
+		module(MODULE_START_ID, 2, ""),
+		record(PERF_INMODULE_START_ID, 0, "in the nameless driver"),
+		module(MODULE_END_ID, 2, ""),
+		record(PERF_INMODULE_END_ID, 0, "in DxeCore again"),
+		module(MODULE_END_ID, 1, ""),
+		record(PERF_FUNCTION_END_ID, 0, "outside again"),
+	}
+	nameless := PhasePair{Start: records[3]}.Name()
+	want := []string{"", "DxeCore", "DxeCore", nameless, nameless, nameless, "DxeCore", "DxeCore", ""}
+
+	assigned := AssignPhases(records)
+	var got []string
+	for _, r := range assigned {
+		got = append(got, r.Phase)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AssignPhases() phases = %q, want %q", got, want)
+	}
+	if records[2].Phase != "" {
+		t.Errorf("AssignPhases() modified its input")
+	}
+}
+
+func TestPhasePairDurationUnderflow(t *testing.T) {
+	p := PhasePair{Start: record(MODULE_START_ID, 100, ""), End: record(MODULE_END_ID, 10, "")}
+	if d := p.Duration(); d != 0 {
+		t.Errorf("Duration() = %v, want 0", d)
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 0, "SEC"),
+		record(MODULE_END_ID, 10, "SEC"),
+		record(MODULE_START_ID, 10, "PEI"),
+		record(PERF_INMODULE_START_ID, 20, "memory init"),
+		record(PERF_INMODULE_END_ID, 30, "memory init"),
+		record(PERF_FUNCTION_START_ID, 40, "fsp"),
+		record(PERF_CALLBACK_START_ID, 45, "callback"),
+		record(PERF_CALLBACK_END_ID, 60, "callback"),
+		record(PERF_FUNCTION_END_ID, 80, "fsp"),
+		record(MODULE_END_ID, 100, "PEI"),
+		record(MODULE_START_ID, 100, "DXE"),
+		record(MODULE_END_ID, 150, "DXE"),
+	}
+	var got []string
+	for _, p := range CriticalPath(records) {
+		got = append(got, p.Name())
+	}
+	if want := []string{"PEI", "fsp", "callback"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CriticalPath() = %q, want %q", got, want)
+	}
+	if got := CriticalPath(nil); len(got) != 0 {
+		t.Errorf("CriticalPath(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/u-root/u-root/pkg/uefivars"
+)
+
+// PhaseRequirement is a phase that has to appear in the records, identified
+// by its START hook and GUID, and the longest it may take.
+type PhaseRequirement struct {
+	// HookID is the START hook of the phase, e.g. MODULE_START_ID.
+	HookID uint16
+	GUID   uefivars.MixedGUID
+	// MaxDuration is the longest the phase may take, 0 only requires the
+	// phase to appear.
+	MaxDuration time.Duration
+}
+
+// Profile is the set of phases expected in the records of a firmware build.
+type Profile []PhaseRequirement
+
+// CheckProfile checks records against profile and returns an error for every
+// requirement that is not met: for phases that do not appear, and for phases
+// of which an occurrence took longer than allowed. It is meant for CI jobs
+// guarding against boot time regressions of firmware builds.
+func CheckProfile(records []MEASUREMENT_RECORD, profile Profile) []error {
+	phases := PairPhases(records)
+	var errs []error
+	for _, req := range profile {
+		found := false
+		var longest PhasePair
+		for _, phase := range phases {
+			if phase.Start.HookID != req.HookID || phase.Start.GUID != req.GUID {
+				continue
+			}
+			if !found || phase.Duration() > longest.Duration() {
+				longest = phase
+			}
+			found = true
+		}
+		hook, ok := eventTypeMap[req.HookID]
+		if !ok {
+			hook = fmt.Sprintf("%#x", req.HookID)
+		}
+		name := fmt.Sprintf("%s %s", hook, req.GUID)
+		switch {
+		case !found:
+			errs = append(errs, fmt.Errorf("required phase %s not found", name))
+		case req.MaxDuration > 0 && longest.Duration() > req.MaxDuration:
+			errs = append(errs, fmt.Errorf("phase %s (%s) took %v, want at most %v", name, longest.Name(), longest.Duration(), req.MaxDuration))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/u-root/u-root/pkg/uefivars"
+)
+
+func TestCheckProfile(t *testing.T) {
+	pei := uefivars.MixedGUID{1}
+	dxe := uefivars.MixedGUID{2}
+	bds := uefivars.MixedGUID{3}
+	withGUID := func(r MEASUREMENT_RECORD, guid uefivars.MixedGUID) MEASUREMENT_RECORD {
+		r.GUID = guid
+		return r
+	}
+	records := []MEASUREMENT_RECORD{
+		withGUID(record(MODULE_START_ID, 0, "PEI"), pei),
+		withGUID(record(MODULE_END_ID, 100, "PEI"), pei),
+		withGUID(record(MODULE_START_ID, 100, "DXE"), dxe),
+		withGUID(record(MODULE_END_ID, 600, "DXE"), dxe),
+	}
+	profile := Profile{
+		{HookID: MODULE_START_ID, GUID: pei, MaxDuration: 200 * time.Nanosecond},
+		{HookID: MODULE_START_ID, GUID: dxe, MaxDuration: 400 * time.Nanosecond},
+		{HookID: MODULE_START_ID, GUID: bds},
+		{HookID: MODULE_START_ID, GUID: dxe},
+	}
+	errs := CheckProfile(records, profile)
+	if len(errs) != 2 {
+		t.Fatalf("CheckProfile() = %v, want 2 errors", errs)
+	}
+	for i, want := range []string{"DXE) took 500ns, want at most 400ns", "MODULE_START_ID " + bds.String() + " not found"} {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("CheckProfile()[%d] = %v, want it to contain %q", i, errs[i], want)
+		}
+	}
+
+	if errs := CheckProfile(records, profile[:1]); len(errs) != 0 {
+		t.Errorf("CheckProfile(met profile) = %v, want no errors", errs)
+	}
+}
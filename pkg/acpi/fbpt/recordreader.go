@@ -0,0 +1,70 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// RecordReader yields the dynamic string event records of an FBPT held in a
+// byte slice one at a time, e.g. of a table saved with DumpTable or built by
+// hand in a test.
+type RecordReader struct {
+	records []byte
+	offset  int
+	err     error
+}
+
+// NewRecordReader returns a RecordReader for tableBytes, the FBPT including
+// its header. Bytes past the table length in the header are ignored.
+func NewRecordReader(tableBytes []byte) (*RecordReader, error) {
+	if len(tableBytes) < EFI_ACPI_5_0_FBPT_HEADER_SIZE {
+		return nil, fmt.Errorf("FBPT truncated to %d bytes", len(tableBytes))
+	}
+	if sig := string(tableBytes[:4]); sig != FBPTStructureSig {
+		return nil, errors.New("FBPT structure signature check failed. Expected: FBPT, Got: " + sig)
+	}
+	tablelength := binary.LittleEndian.Uint32(tableBytes[4:])
+	if tablelength < EFI_ACPI_5_0_FBPT_HEADER_SIZE || uint64(tablelength) > uint64(len(tableBytes)) {
+		return nil, fmt.Errorf("FBPT table length %d does not fit the %d bytes given", tablelength, len(tableBytes))
+	}
+	return &RecordReader{records: tableBytes[EFI_ACPI_5_0_FBPT_HEADER_SIZE:tablelength]}, nil
+}
+
+// Next returns the next dynamic string event record, skipping records of
+// other types, or io.EOF after the last one. A record that cannot be decoded
+// is an error, which all later calls return as well.
+func (r *RecordReader) Next() (MEASUREMENT_RECORD, error) {
+	for r.err == nil {
+		if r.offset == len(r.records) {
+			r.err = io.EOF
+			break
+		}
+		hdr, payload, err := splitRecord(r.records[r.offset:])
+		if err != nil {
+			r.err = fmt.Errorf("record at table offset %d: %w", r.offset, err)
+			break
+		}
+		offset := r.offset
+		r.offset += int(hdr.Length)
+		if hdr.Type != FPDT_DYNAMIC_STRING_EVENT_RECORD_IDENTIFIER {
+			continue
+		}
+		if hdr.Revision != FPDT_DYNAMIC_STRING_EVENT_RECORD_REVISION {
+			r.err = fmt.Errorf("record at table offset %d: unknown dynamic string event record revision %d", offset, hdr.Revision)
+			break
+		}
+		record, err := parseDynamicRecord(payload)
+		if err != nil {
+			r.err = fmt.Errorf("record at table offset %d: %w", offset, err)
+			break
+		}
+		return record, nil
+	}
+	return MEASUREMENT_RECORD{}, r.err
+}
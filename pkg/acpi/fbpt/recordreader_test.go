@@ -0,0 +1,66 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"io"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/acpi/fbpt/fbpttest"
+)
+
+func TestRecordReader(t *testing.T) {
+	table := fakeMem(
+		dynamicRecord(MODULE_START_ID, 100, "PEI"),
+		fbpttest.BasicBootRecord(1, 2, 3, 4, 5),
+		dynamicRecord(MODULE_END_ID, 200, "PEI"),
+	)[tableAddr:]
+	r, err := NewRecordReader(append(table, 0xff, 0xff))
+	if err != nil {
+		t.Fatalf("NewRecordReader() = _, %v, want nil", err)
+	}
+	for _, want := range []uint64{100, 200} {
+		record, err := r.Next()
+		if err != nil || record.Timestamp != want || record.Description != "PEI" {
+			t.Fatalf("Next() = %+v, %v, want the PEI record at %d, nil", record, err, want)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Next(); err != io.EOF {
+			t.Errorf("Next() = _, %v, want %v", err, io.EOF)
+		}
+	}
+}
+
+func TestRecordReaderInvalid(t *testing.T) {
+	table := fakeMem(dynamicRecord(MODULE_START_ID, 100, "PEI"))[tableAddr:]
+
+	for _, tt := range []struct {
+		name  string
+		table []byte
+	}{
+		{name: "truncated header", table: table[:4]},
+		{name: "bad signature", table: append([]byte("FPDT"), table[4:]...)},
+		{name: "truncated table", table: table[:len(table)-1]},
+	} {
+		if _, err := NewRecordReader(tt.table); err == nil {
+			t.Errorf("NewRecordReader(%s) = _, nil, want error", tt.name)
+		}
+	}
+
+	bad := append([]byte(nil), table...)
+	bad[EFI_ACPI_5_0_FBPT_HEADER_SIZE+2] = 2
+	r, err := NewRecordReader(bad)
+	if err != nil {
+		t.Fatalf("NewRecordReader() = _, %v, want nil", err)
+	}
+	_, err = r.Next()
+	if err == nil || err == io.EOF {
+		t.Errorf("Next() with invalid record length = _, %v, want error", err)
+	}
+	if _, err2 := r.Next(); err2 != err {
+		t.Errorf("Next() after error = _, %v, want %v", err2, err)
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/u-root/u-root/pkg/uefivars"
+)
+
+// Record is a decoded FBPT record: one of *DynamicStringRecord,
+// *BasicBootRecord, *GUIDEventRecord, *DualGUIDStringRecord,
+// *GUIDQwordRecord, *GUIDQwordStringRecord and *UnknownRecord.
+type Record interface {
+	// Header returns the header of the record.
+	Header() EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER
+}
+
+// DynamicStringRecord is an FPDT_DYNAMIC_STRING_EVENT_TYPE record, as
+// returned by FindAllFBPTRecords.
+type DynamicStringRecord struct {
+	PerformanceRecordHeader EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER
+	MEASUREMENT_RECORD
+}
+
+// BasicBootRecord is the firmware basic boot record.
+type BasicBootRecord struct {
+	EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD
+}
+
+// GUIDEventRecord is an FPDT_GUID_EVENT_TYPE record.
+type GUIDEventRecord struct {
+	PerformanceRecordHeader EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER
+	ProgressID              uint16
+	ApicID                  uint32
+	Timestamp               uint64
+	GUID                    uefivars.MixedGUID
+}
+
+// DualGUIDStringRecord is an FPDT_DUAL_GUID_STRING_EVENT_TYPE record.
+type DualGUIDStringRecord struct {
+	PerformanceRecordHeader EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER
+	ProgressID              uint16
+	ApicID                  uint32
+	Timestamp               uint64
+	GUID1                   uefivars.MixedGUID
+	GUID2                   uefivars.MixedGUID
+	String                  string
+}
+
+// GUIDQwordRecord is an FPDT_GUID_QWORD_EVENT_TYPE record.
+type GUIDQwordRecord struct {
+	PerformanceRecordHeader EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER
+	ProgressID              uint16
+	ApicID                  uint32
+	Timestamp               uint64
+	GUID                    uefivars.MixedGUID
+	Qword                   uint64
+}
+
+// GUIDQwordStringRecord is an FPDT_GUID_QWORD_STRING_EVENT_TYPE record.
+type GUIDQwordStringRecord struct {
+	PerformanceRecordHeader EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER
+	ProgressID              uint16
+	ApicID                  uint32
+	Timestamp               uint64
+	GUID                    uefivars.MixedGUID
+	Qword                   uint64
+	String                  string
+}
+
+// UnknownRecord is a record of a type this package does not decode.
+type UnknownRecord struct {
+	PerformanceRecordHeader EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER
+	Payload                 []byte
+}
+
+// Header implements Record.
+func (r *DynamicStringRecord) Header() EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER {
+	return r.PerformanceRecordHeader
+}
+
+// Header implements Record.
+func (r *BasicBootRecord) Header() EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER {
+	return r.PerformanceRecordHeader
+}
+
+// Header implements Record.
+func (r *GUIDEventRecord) Header() EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER {
+	return r.PerformanceRecordHeader
+}
+
+// Header implements Record.
+func (r *DualGUIDStringRecord) Header() EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER {
+	return r.PerformanceRecordHeader
+}
+
+// Header implements Record.
+func (r *GUIDQwordRecord) Header() EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER {
+	return r.PerformanceRecordHeader
+}
+
+// Header implements Record.
+func (r *GUIDQwordStringRecord) Header() EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER {
+	return r.PerformanceRecordHeader
+}
+
+// Header implements Record.
+func (r *UnknownRecord) Header() EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER {
+	return r.PerformanceRecordHeader
+}
+
+// guidEventFixedSize is the size of ProgressID, ApicID, Timestamp and GUID
+// that start all GUID event records.
+const guidEventFixedSize = 30
+
+func guidAt(b []byte) uefivars.MixedGUID {
+	var guid uefivars.MixedGUID
+	copy(guid[:], b)
+	return guid
+}
+
+// parseRecord decodes the record with header hdr and payload.
+func parseRecord(hdr EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, payload []byte) (Record, error) {
+	minSize := map[uint16]int{
+		FPDT_GUID_EVENT_TYPE:              guidEventFixedSize,
+		FPDT_DUAL_GUID_STRING_EVENT_TYPE:  guidEventFixedSize + 16,
+		FPDT_GUID_QWORD_EVENT_TYPE:        guidEventFixedSize + 8,
+		FPDT_GUID_QWORD_STRING_EVENT_TYPE: guidEventFixedSize + 8,
+	}
+	if size, ok := minSize[hdr.Type]; ok && len(payload) < size {
+		return nil, fmt.Errorf("record of type %#x too short: %d bytes", hdr.Type, len(payload))
+	}
+	progressID := func() uint16 { return binary.LittleEndian.Uint16(payload[0:2]) }
+	apicID := func() uint32 { return binary.LittleEndian.Uint32(payload[2:6]) }
+	timestamp := func() uint64 { return binary.LittleEndian.Uint64(payload[6:14]) }
+
+	switch hdr.Type {
+	case FPDT_DYNAMIC_STRING_EVENT_TYPE:
+		record, err := parseDynamicRecord(payload)
+		if err != nil {
+			return nil, err
+		}
+		return &DynamicStringRecord{PerformanceRecordHeader: hdr, MEASUREMENT_RECORD: record}, nil
+	case EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_FIRMWARE_BASIC_BOOT:
+		record, err := parseBasicBootRecord(hdr, payload)
+		if err != nil {
+			return nil, err
+		}
+		return &BasicBootRecord{record}, nil
+	case FPDT_GUID_EVENT_TYPE:
+		return &GUIDEventRecord{
+			PerformanceRecordHeader: hdr,
+			ProgressID:              progressID(),
+			ApicID:                  apicID(),
+			Timestamp:               timestamp(),
+			GUID:                    guidAt(payload[14:30]),
+		}, nil
+	case FPDT_DUAL_GUID_STRING_EVENT_TYPE:
+		return &DualGUIDStringRecord{
+			PerformanceRecordHeader: hdr,
+			ProgressID:              progressID(),
+			ApicID:                  apicID(),
+			Timestamp:               timestamp(),
+			GUID1:                   guidAt(payload[14:30]),
+			GUID2:                   guidAt(payload[30:46]),
+			String:                  string(payload[46:]),
+		}, nil
+	case FPDT_GUID_QWORD_EVENT_TYPE:
+		return &GUIDQwordRecord{
+			PerformanceRecordHeader: hdr,
+			ProgressID:              progressID(),
+			ApicID:                  apicID(),
+			Timestamp:               timestamp(),
+			GUID:                    guidAt(payload[14:30]),
+			Qword:                   binary.LittleEndian.Uint64(payload[30:38]),
+		}, nil
+	case FPDT_GUID_QWORD_STRING_EVENT_TYPE:
+		return &GUIDQwordStringRecord{
+			PerformanceRecordHeader: hdr,
+			ProgressID:              progressID(),
+			ApicID:                  apicID(),
+			Timestamp:               timestamp(),
+			GUID:                    guidAt(payload[14:30]),
+			Qword:                   binary.LittleEndian.Uint64(payload[30:38]),
+			String:                  string(payload[38:]),
+		}, nil
+	default:
+		return &UnknownRecord{PerformanceRecordHeader: hdr, Payload: append([]byte(nil), payload...)}, nil
+	}
+}
+
+// ReadAllRecords returns all records of the FBPT at addr, decoded into the
+// Record type matching their type. Unlike FindAllFBPTRecords it is strict:
+// it fails on the first record that cannot be decoded.
+func ReadAllRecords(addr uint64) ([]Record, error) {
+	f, err := os.OpenFile(memDevice, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readAllRecords(f, addr)
+}
+
+func readAllRecords(mem io.ReaderAt, addr uint64) ([]Record, error) {
+	var records []Record
+	err := Walk(mem, addr, func(hdr EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, payload []byte) error {
+		record, err := parseRecord(hdr, payload)
+		if err != nil {
+			return fmt.Errorf("record %d: %w", len(records), err)
+		}
+		records = append(records, record)
+		return nil
+	})
+	return records, err
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/acpi/fbpt/fbpttest"
+)
+
+// guidRecord returns a GUID event record of recordType with extra appended
+// after the fixed fields.
+func guidRecord(recordType uint16, progressID uint16, timestamp uint64, extra []byte) []byte {
+	b := make([]byte, EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE+guidEventFixedSize, EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE+guidEventFixedSize+len(extra))
+	b = append(b, extra...)
+	binary.LittleEndian.PutUint16(b[0:], recordType)
+	b[2] = uint8(len(b))
+	b[3] = FPDT_RECORD_REVISION_1
+	binary.LittleEndian.PutUint16(b[4:], progressID)
+	binary.LittleEndian.PutUint32(b[6:], 2)
+	binary.LittleEndian.PutUint64(b[10:], timestamp)
+	b[18] = 0xaa
+	return b
+}
+
+func TestReadAllRecords(t *testing.T) {
+	qword := make([]byte, 8)
+	binary.LittleEndian.PutUint64(qword, 42)
+	guid2 := make([]byte, 16)
+	guid2[0] = 0xbb
+	mem := fakeMem(
+		fbpttest.BasicBootRecord(1, 2, 3, 4, 5),
+		dynamicRecord(MODULE_START_ID, 10, "PEI"),
+		guidRecord(FPDT_GUID_EVENT_TYPE, MODULE_END_ID, 20, nil),
+		guidRecord(FPDT_DUAL_GUID_STRING_EVENT_TYPE, PERF_EVENTSIGNAL_START_ID, 30, append(guid2, "event"...)),
+		guidRecord(FPDT_GUID_QWORD_EVENT_TYPE, MODULE_DB_START_ID, 40, qword),
+		guidRecord(FPDT_GUID_QWORD_STRING_EVENT_TYPE, MODULE_DB_END_ID, 50, append(qword, "driver"...)),
+		otherRecord(0x0003, 0x10),
+	)
+	records, err := readAllRecords(bytes.NewReader(mem), tableAddr)
+	if err != nil {
+		t.Fatalf("readAllRecords() = _, %v, want nil", err)
+	}
+	if len(records) != 7 {
+		t.Fatalf("readAllRecords() returned %d records, want 7", len(records))
+	}
+	for i, r := range records {
+		switch r := r.(type) {
+		case *BasicBootRecord:
+			if r.ExitBootServicesExit != 5 || r.BootDuration() != 4 {
+				t.Errorf("records[%d] = %+v, want ExitBootServicesExit 5", i, r)
+			}
+		case *DynamicStringRecord:
+			if r.Description != "PEI" || r.Timestamp != 10 {
+				t.Errorf("records[%d] = %+v, want PEI at 10", i, r)
+			}
+		case *GUIDEventRecord:
+			if r.ProgressID != MODULE_END_ID || r.ApicID != 2 || r.Timestamp != 20 || r.GUID[0] != 0xaa {
+				t.Errorf("records[%d] = %+v, want MODULE_END_ID at 20", i, r)
+			}
+		case *DualGUIDStringRecord:
+			if r.GUID2[0] != 0xbb || r.String != "event" || r.Timestamp != 30 {
+				t.Errorf("records[%d] = %+v, want event at 30", i, r)
+			}
+		case *GUIDQwordRecord:
+			if r.Qword != 42 || r.Timestamp != 40 {
+				t.Errorf("records[%d] = %+v, want qword 42 at 40", i, r)
+			}
+		case *GUIDQwordStringRecord:
+			if r.Qword != 42 || r.String != "driver" || r.Timestamp != 50 {
+				t.Errorf("records[%d] = %+v, want driver with qword 42 at 50", i, r)
+			}
+		case *UnknownRecord:
+			if r.Header().Type != 0x0003 || len(r.Payload) != 0x10-EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE {
+				t.Errorf("records[%d] = %+v, want type 0x3 with 12 bytes payload", i, r)
+			}
+		default:
+			t.Errorf("records[%d] has unexpected type %T", i, r)
+		}
+	}
+
+	truncated := guidRecord(FPDT_GUID_QWORD_EVENT_TYPE, MODULE_DB_START_ID, 40, nil)
+	if _, err := readAllRecords(bytes.NewReader(fakeMem(truncated)), tableAddr); err == nil {
+		t.Errorf("readAllRecords(truncated GUID QWORD record) = _, nil, want error")
+	}
+}
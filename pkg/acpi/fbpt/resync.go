@@ -0,0 +1,58 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+// recordLayout is the revision and minimum length of a known record type.
+type recordLayout struct {
+	revision  uint8
+	minLength uint8
+}
+
+// based on struct definitions found in edk2: /MdeModulePkg/Include/Guid/ExtendedFirmwarePerformance.h
+var recordLayouts = map[uint16]recordLayout{
+	EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_FIRMWARE_BASIC_BOOT: {EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_REVISION_FIRMWARE_BASIC_BOOT, 48},
+	FPDT_GUID_EVENT_TYPE:              {FPDT_RECORD_REVISION_1, 34},
+	FPDT_DYNAMIC_STRING_EVENT_TYPE:    {FPDT_RECORD_REVISION_1, 34},
+	FPDT_DUAL_GUID_STRING_EVENT_TYPE:  {FPDT_RECORD_REVISION_1, 50},
+	FPDT_GUID_QWORD_EVENT_TYPE:        {FPDT_RECORD_REVISION_1, 42},
+	FPDT_GUID_QWORD_STRING_EVENT_TYPE: {FPDT_RECORD_REVISION_1, 42},
+}
+
+// fitsAt reports whether a record header at offset off of table declares a
+// length that is usable and within table.
+func fitsAt(table []byte, off int) bool {
+	if off+EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE > len(table) {
+		return false
+	}
+	length := int(table[off+2])
+	return length >= EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE && off+length <= len(table)
+}
+
+// knownRecordAt reports whether a record of a known type, revision and
+// length starts at offset off of table.
+func knownRecordAt(table []byte, off int) bool {
+	if !fitsAt(table, off) {
+		return false
+	}
+	hdr := parseRecordHeader(table[off:])
+	layout, ok := recordLayouts[hdr.Type]
+	return ok && hdr.Revision == layout.revision && hdr.Length >= layout.minLength
+}
+
+// resyncOffset returns the offset of the first plausible record in table
+// after its first byte. A record is plausible if it is of a known type and is
+// either followed by another usable record header or ends the table.
+func resyncOffset(table []byte) (int, bool) {
+	for off := 1; off < len(table); off++ {
+		if !knownRecordAt(table, off) {
+			continue
+		}
+		next := off + int(table[off+2])
+		if next == len(table) || fitsAt(table, next) {
+			return off, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	S3PTStructureSig = "S3PT"
+
+	// see ACPI Table Spec: https://uefi.org/sites/default/files/resources/ACPI%206_2_A_Sept29.pdf (page 213)
+	EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_S3_RESUME  = 0x0000
+	EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_S3_SUSPEND = 0x0001
+
+	EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_REVISION_S3_RESUME  = 0x01
+	EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_REVISION_S3_SUSPEND = 0x01
+
+	EFI_ACPI_5_0_S3PT_HEADER_SIZE = 8
+
+	// record lengths including the header
+	s3ResumeRecordLength  = 24
+	s3SuspendRecordLength = 20
+)
+
+// S3Performance holds the timing of the last S3 suspend and resume, taken
+// from the basic S3 resume and suspend records of the S3 Performance Table.
+// All timestamps are in nanoseconds.
+type S3Performance struct {
+	// ResumeCount is the number of resumes since the last cold boot
+	ResumeCount uint32
+	// FullResume is the duration of the last resume, from the reset vector to
+	// the handoff to the OS waking vector
+	FullResume uint64
+	// AverageResume is the average duration of all resumes since cold boot
+	AverageResume uint64
+	// SuspendStart and SuspendEnd are when the OS wrote SLP_TYP to enter S3
+	// and when the firmware finished the suspend
+	SuspendStart uint64
+	SuspendEnd   uint64
+}
+
+// SuspendDuration returns SuspendEnd - SuspendStart, or 0 if the timestamps
+// are inconsistent.
+func (p S3Performance) SuspendDuration() uint64 {
+	if p.SuspendEnd < p.SuspendStart {
+		return 0
+	}
+	return p.SuspendEnd - p.SuspendStart
+}
+
+// ReadS3Performance reads the S3 Performance Table at S3PTAddr, see
+// fpdt.FindS3PTTableAddr. A system that has not been through a suspend and
+// resume yet reports zero values.
+func ReadS3Performance(S3PTAddr uint64) (S3Performance, error) {
+	f, err := os.OpenFile(memDevice, os.O_RDONLY, 0)
+	if err != nil {
+		return S3Performance{}, err
+	}
+	defer f.Close()
+	return readS3Performance(f, S3PTAddr)
+}
+
+func readS3Performance(mem io.ReaderAt, S3PTAddr uint64) (S3Performance, error) {
+	var perf S3Performance
+
+	var header [EFI_ACPI_5_0_S3PT_HEADER_SIZE]byte
+	if err := readFullAt(mem, header[:], S3PTAddr); err != nil {
+		return perf, err
+	}
+	if string(header[:4]) != S3PTStructureSig {
+		return perf, errors.New("S3PT structure signature check failed. Expected: S3PT, Got: " + string(header[:4]))
+	}
+	tableLength := binary.LittleEndian.Uint32(header[4:])
+	if tableLength < EFI_ACPI_5_0_S3PT_HEADER_SIZE || tableLength > maxPlausibleFBPTLength {
+		return perf, fmt.Errorf("S3PT table length %d is implausible", tableLength)
+	}
+
+	table := make([]byte, tableLength-EFI_ACPI_5_0_S3PT_HEADER_SIZE)
+	if err := readFullAt(mem, table, S3PTAddr+EFI_ACPI_5_0_S3PT_HEADER_SIZE); err != nil {
+		return perf, err
+	}
+	for offset := 0; offset+EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE <= len(table); {
+		hdr, payload, err := splitRecord(table[offset:])
+		if err != nil {
+			return perf, fmt.Errorf("S3PT record at table offset %d: %w", offset, err)
+		}
+		switch hdr.Type {
+		case EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_S3_RESUME:
+			if int(hdr.Length) < s3ResumeRecordLength {
+				return perf, fmt.Errorf("S3 resume record too short: %d bytes", hdr.Length)
+			}
+			perf.ResumeCount = binary.LittleEndian.Uint32(payload[0:])
+			perf.FullResume = binary.LittleEndian.Uint64(payload[4:])
+			perf.AverageResume = binary.LittleEndian.Uint64(payload[12:])
+		case EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_S3_SUSPEND:
+			if int(hdr.Length) < s3SuspendRecordLength {
+				return perf, fmt.Errorf("S3 suspend record too short: %d bytes", hdr.Length)
+			}
+			perf.SuspendStart = binary.LittleEndian.Uint64(payload[0:])
+			perf.SuspendEnd = binary.LittleEndian.Uint64(payload[8:])
+		}
+		offset += int(hdr.Length)
+	}
+	return perf, nil
+}
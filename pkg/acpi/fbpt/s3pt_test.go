@@ -0,0 +1,66 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// fakeS3PTMem returns memory holding an S3PT made of the given records at tableAddr.
+func fakeS3PTMem(records ...[]byte) []byte {
+	mem := fakeMem(records...)
+	copy(mem[tableAddr:], S3PTStructureSig)
+	return mem
+}
+
+func s3Record(recordType uint16, length uint8, values ...uint64) []byte {
+	b := otherRecord(recordType, length)
+	b[3] = 1
+	offset := 4
+	if recordType == EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_S3_RESUME {
+		binary.LittleEndian.PutUint32(b[4:], uint32(values[0]))
+		values = values[1:]
+		offset = 8
+	}
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(b[offset+8*i:], v)
+	}
+	return b
+}
+
+func TestReadS3Performance(t *testing.T) {
+	mem := fakeS3PTMem(
+		s3Record(EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_S3_RESUME, s3ResumeRecordLength, 3, 500, 400),
+		s3Record(EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_S3_SUSPEND, s3SuspendRecordLength, 1000, 1300),
+	)
+	got, err := readS3Performance(bytes.NewReader(mem), tableAddr)
+	if err != nil {
+		t.Fatalf("readS3Performance() = _, %v, want nil", err)
+	}
+	want := S3Performance{ResumeCount: 3, FullResume: 500, AverageResume: 400, SuspendStart: 1000, SuspendEnd: 1300}
+	if got != want {
+		t.Errorf("readS3Performance() = %+v, want %+v", got, want)
+	}
+	if d := got.SuspendDuration(); d != 300 {
+		t.Errorf("SuspendDuration() = %d, want 300", d)
+	}
+
+	invalidLength := otherRecord(EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_S3_SUSPEND, 8)
+	invalidLength[2] = 2
+	for _, tt := range []struct {
+		name string
+		mem  []byte
+	}{
+		{name: "FBPT signature", mem: fakeMem()},
+		{name: "short resume record", mem: fakeS3PTMem(s3Record(EFI_ACPI_5_0_FPDT_RUNTIME_RECORD_TYPE_S3_RESUME, 16, 3, 500))},
+		{name: "invalid length", mem: fakeS3PTMem(invalidLength)},
+	} {
+		if _, err := readS3Performance(bytes.NewReader(tt.mem), tableAddr); err == nil {
+			t.Errorf("readS3Performance(%s) = _, nil, want error", tt.name)
+		}
+	}
+}
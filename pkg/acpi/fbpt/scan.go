@@ -0,0 +1,119 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// amount of memory read at once by ScanForFBPT
+	scanChunkSize = 64 * 1024
+
+	// largest FBPT length considered plausible by ScanForFBPT
+	maxPlausibleFBPTLength = EFI_ACPI_5_0_FBPT_HEADER_SIZE + maxNumberOfFBPTPerfRecords*0xFF
+)
+
+// ErrFBPTNotFound is returned when no FBPT is found in the scanned range.
+var ErrFBPTNotFound = errors.New("FBPT signature not found")
+
+// ScanForFBPT searches the length bytes of r starting at start for the FBPT
+// signature followed by a plausible table length, and returns the address of
+// the first match. It is meant as last resort for firmware whose FPDT does
+// not point to the FBPT.
+func ScanForFBPT(r io.ReaderAt, start, length uint64) (uint64, error) {
+	sig := []byte(FBPTStructureSig)
+	// Chunks overlap by a header minus one byte so that no header is split.
+	buf := make([]byte, scanChunkSize+EFI_ACPI_5_0_FBPT_HEADER_SIZE-1)
+	for offset := uint64(0); offset < length; offset += scanChunkSize {
+		want := uint64(len(buf))
+		if length-offset < want {
+			want = length - offset
+		}
+		n, err := io.ReadFull(io.NewSectionReader(r, int64(start+offset), int64(want)), buf[:want])
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+
+		chunk := buf[:n]
+		for i := 0; ; {
+			j := bytes.Index(chunk[i:], sig)
+			if j < 0 {
+				break
+			}
+			pos := i + j
+			if pos+EFI_ACPI_5_0_FBPT_HEADER_SIZE <= len(chunk) {
+				tableLength := binary.LittleEndian.Uint32(chunk[pos+4:])
+				if tableLength >= EFI_ACPI_5_0_FBPT_HEADER_SIZE && tableLength <= maxPlausibleFBPTLength {
+					return start + offset + uint64(pos), nil
+				}
+			}
+			i = pos + 1
+		}
+
+		if uint64(n) < want {
+			break
+		}
+	}
+	return 0, ErrFBPTNotFound
+}
+
+// LocateFBPT is like ScanForFBPT, but only accepts a table that lies within the
+// scanned range and whose records, of whatever type, exactly fill it, and
+// otherwise moves on to the next match. It is the recovery path for firmware that does not publish an
+// FPDT at all.
+func LocateFBPT(r io.ReaderAt, searchStart, searchLen uint64) (uint64, error) {
+	end := searchStart + searchLen
+	// The reason the last candidate was rejected, to explain a failed search.
+	var rejected error
+	for start := searchStart; start < end; {
+		addr, err := ScanForFBPT(r, start, end-start)
+		if err != nil {
+			if errors.Is(err, ErrFBPTNotFound) && rejected != nil {
+				return 0, fmt.Errorf("%w, last candidate rejected: %v", err, rejected)
+			}
+			return 0, err
+		}
+		start = addr + 1
+
+		tableLength, err := verifyFBPTSignature(r, addr)
+		if err != nil {
+			rejected = fmt.Errorf("%#x: %w", addr, err)
+			continue
+		}
+		if addr+uint64(tableLength) > end {
+			rejected = fmt.Errorf("%#x: table length %d runs past the end of the range", addr, tableLength)
+			continue
+		}
+		// Unlike the Scanner, a strict walk rejects any table that does
+		// not split cleanly into records, as random memory seldom does.
+		if err := walk(r, addr, tableLength, func(uint32, EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, []byte) error { return nil }, nil); err != nil {
+			rejected = fmt.Errorf("%#x: %w", addr, err)
+			continue
+		}
+		return addr, nil
+	}
+	if rejected != nil {
+		return 0, fmt.Errorf("%w, last candidate rejected: %v", ErrFBPTNotFound, rejected)
+	}
+	return 0, ErrFBPTNotFound
+}
+
+// LocateAndScan returns the dynamic string event records of the FBPT found by
+// LocateFBPT in the searchLen bytes of r starting at searchStart. Records that
+// cannot be decoded are skipped like by FindAllFBPTRecords; use LocateFBPT
+// and Scanner.ReadFBPTRecords to get them reported as warnings.
+func LocateAndScan(r io.ReaderAt, searchStart, searchLen uint64) ([]MEASUREMENT_RECORD, error) {
+	addr, err := LocateFBPT(r, searchStart, searchLen)
+	if err != nil {
+		return nil, err
+	}
+	records, _, err := (&Scanner{}).findAllFBPTRecords(r, addr)
+	return records, err
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestScanForFBPT(t *testing.T) {
+	table := fakeMem(dynamicRecord(MODULE_START_ID, 100, "PEI"))[tableAddr:]
+
+	// A signature with an implausible length must be skipped.
+	decoy := []byte(FBPTStructureSig + "\x00\x00\x00\x00")
+
+	for _, tt := range []struct {
+		name   string
+		offset int
+	}{
+		{name: "start", offset: 0},
+		{name: "unaligned", offset: 0x123},
+		// Header straddles the chunk boundary.
+		{name: "chunk boundary", offset: scanChunkSize - 2},
+		{name: "second chunk", offset: scanChunkSize + 0x40},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := make([]byte, 3*scanChunkSize)
+			copy(mem[0x10:], decoy)
+			copy(mem[0x20+tt.offset:], table)
+
+			got, err := ScanForFBPT(bytes.NewReader(mem), 0x20, uint64(len(mem)-0x20))
+			if err != nil {
+				t.Fatalf("ScanForFBPT() = _, %v, want nil", err)
+			}
+			if want := uint64(0x20 + tt.offset); got != want {
+				t.Errorf("ScanForFBPT() = %#x, want %#x", got, want)
+			}
+		})
+	}
+}
+
+func TestScanForFBPTNotFound(t *testing.T) {
+	mem := make([]byte, scanChunkSize)
+	copy(mem[0x10:], FBPTStructureSig+"\x00\x00\x00\x00")
+	if _, err := ScanForFBPT(bytes.NewReader(mem), 0, uint64(len(mem))); !errors.Is(err, ErrFBPTNotFound) {
+		t.Errorf("ScanForFBPT() = _, %v, want %v", err, ErrFBPTNotFound)
+	}
+	// The range may extend past the end of the readable memory.
+	if _, err := ScanForFBPT(bytes.NewReader(mem), 0, 2*uint64(len(mem))); !errors.Is(err, ErrFBPTNotFound) {
+		t.Errorf("ScanForFBPT() = _, %v, want %v", err, ErrFBPTNotFound)
+	}
+}
+
+func TestLocateAndScan(t *testing.T) {
+	table := fakeMem(
+		dynamicRecord(MODULE_START_ID, 100, "PEI"),
+		dynamicRecord(MODULE_END_ID, 200, "PEI"),
+	)[tableAddr:]
+	mem := make([]byte, 0x200)
+	// A plausible length that runs past the end of the range rejects the
+	// first match.
+	copy(mem[0x10:], FBPTStructureSig+"\x00\x10\x00\x00")
+	copy(mem[0x40:], table)
+
+	addr, err := LocateFBPT(bytes.NewReader(mem), 0, uint64(len(mem)))
+	if err != nil || addr != 0x40 {
+		t.Errorf("LocateFBPT() = %#x, %v, want 0x40, nil", addr, err)
+	}
+	records, err := LocateAndScan(bytes.NewReader(mem), 0, uint64(len(mem)))
+	if err != nil {
+		t.Fatalf("LocateAndScan() = _, %v, want nil", err)
+	}
+	if len(records) != 2 || records[1].Timestamp != 200 {
+		t.Errorf("LocateAndScan() = %+v, want the 2 records of the table", records)
+	}
+
+	// The real table does not fit in a range ending in its middle.
+	if _, err := LocateAndScan(bytes.NewReader(mem), 0, 0x50); !errors.Is(err, ErrFBPTNotFound) {
+		t.Errorf("LocateAndScan(truncated range) = _, %v, want %v", err, ErrFBPTNotFound)
+	}
+}
+
+func TestLocateFBPTGarbageCandidate(t *testing.T) {
+	table := fakeMem(dynamicRecord(MODULE_START_ID, 100, "PEI"))[tableAddr:]
+	for stray := 1; stray < EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER_SIZE; stray++ {
+		// A table of one unknown record followed by stray bytes.
+		garbage := fakeMem(otherRecord(0x1234, 0x10), bytes.Repeat([]byte{0xaa}, stray))[tableAddr:]
+		mem := make([]byte, 0x200)
+		copy(mem[0x10:], garbage)
+		copy(mem[0x80:], table)
+
+		addr, err := LocateFBPT(bytes.NewReader(mem), 0, uint64(len(mem)))
+		if err != nil || addr != 0x80 {
+			t.Errorf("LocateFBPT(%d stray bytes) = %#x, %v, want 0x80, nil", stray, addr, err)
+		}
+		records, err := LocateAndScan(bytes.NewReader(mem), 0, uint64(len(mem)))
+		if err != nil || len(records) != 1 || records[0].Timestamp != 100 {
+			t.Errorf("LocateAndScan(%d stray bytes) = %+v, %v, want the record of the table, nil", stray, records, err)
+		}
+	}
+}
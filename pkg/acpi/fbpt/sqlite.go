@@ -0,0 +1,74 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"database/sql"
+	"fmt"
+
+	// Registers the CGo-free "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteTable is the table WriteSQLite appends the records to.
+const SQLiteTable = "fbpt_records"
+
+// sqliteSchema creates SQLiteTable if absent. Records are keyed by the boot
+// they were read on and their index in the table.
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS ` + SQLiteTable + ` (
+	boot_id TEXT NOT NULL,
+	idx INTEGER NOT NULL,
+	hook_id INTEGER NOT NULL,
+	hook_type TEXT NOT NULL,
+	processor_identifier INTEGER NOT NULL,
+	timestamp INTEGER NOT NULL,
+	guid TEXT NOT NULL,
+	description TEXT NOT NULL,
+	driver_name TEXT NOT NULL,
+	PRIMARY KEY (boot_id, idx)
+)`
+
+// WriteSQLite appends records, keyed by bootID, to SQLiteTable of the SQLite
+// database at path, creating the database and the table if absent, so that
+// the records of many boots accumulate in one database. Records already
+// stored for bootID are replaced. All changes are made in a single
+// transaction.
+func WriteSQLite(path string, records []MEASUREMENT_RECORD, bootID string) (err error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := db.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op once the transaction is committed.
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("creating table %s: %w", SQLiteTable, err)
+	}
+	if _, err := tx.Exec("DELETE FROM "+SQLiteTable+" WHERE boot_id = ?", bootID); err != nil {
+		return err
+	}
+	insert, err := tx.Prepare("INSERT INTO " + SQLiteTable + " VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+	for i, record := range records {
+		if _, err := insert.Exec(bootID, i, record.HookID, record.HookType, record.ProcessorIdentifier,
+			int64(record.Timestamp), record.GUID.String(), record.Description, record.DriverName); err != nil {
+			return fmt.Errorf("inserting record %d: %w", i, err)
+		}
+	}
+	return tx.Commit()
+}
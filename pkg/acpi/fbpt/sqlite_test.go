@@ -0,0 +1,72 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "boots.db")
+	for _, w := range []struct {
+		bootID  string
+		records []MEASUREMENT_RECORD
+	}{
+		{"boot-'1'", []MEASUREMENT_RECORD{record(MODULE_START_ID, 1, "stale")}},
+		{"boot-2", []MEASUREMENT_RECORD{record(MODULE_START_ID, 10, "other boot")}},
+		// Rewriting a boot replaces its records.
+		{"boot-'1'", []MEASUREMENT_RECORD{
+			record(MODULE_START_ID, 100, "PEI"),
+			record(MODULE_END_ID, 200, "Bob's\x00 driver"),
+		}},
+	} {
+		if err := WriteSQLite(path, w.records, w.bootID); err != nil {
+			t.Fatalf("WriteSQLite(%q) = %v, want nil", w.bootID, err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("sql.Open() = _, %v, want nil", err)
+	}
+	defer db.Close()
+	rows, err := db.Query("SELECT boot_id, idx, hook_id, hook_type, timestamp, guid, description FROM " + SQLiteTable + " ORDER BY boot_id, idx")
+	if err != nil {
+		t.Fatalf("db.Query() = _, %v, want nil", err)
+	}
+	defer rows.Close()
+	type row struct {
+		bootID      string
+		idx         int
+		hookID      uint16
+		hookType    string
+		timestamp   int64
+		guid        string
+		description string
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.bootID, &r.idx, &r.hookID, &r.hookType, &r.timestamp, &r.guid, &r.description); err != nil {
+			t.Fatalf("rows.Scan() = %v, want nil", err)
+		}
+		got = append(got, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows.Err() = %v, want nil", err)
+	}
+	const zeroGUID = "00000000-0000-0000-0000-000000000000"
+	want := []row{
+		{"boot-'1'", 0, MODULE_START_ID, "MODULE_START_ID", 100, zeroGUID, "PEI"},
+		{"boot-'1'", 1, MODULE_END_ID, "MODULE_END_ID", 200, zeroGUID, "Bob's\x00 driver"},
+		{"boot-2", 0, MODULE_START_ID, "MODULE_START_ID", 10, zeroGUID, "other boot"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteSQLite() stored %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// WriteSummary writes the phases found in records, longest first, with their
+// duration and their share of total, e.g. the BootDuration of the basic boot
+// record.
+func WriteSummary(w io.Writer, records []MEASUREMENT_RECORD, total time.Duration) error {
+	phases := PairPhases(records)
+	sort.SliceStable(phases, func(i, j int) bool {
+		return phases[i].Duration() > phases[j].Duration()
+	})
+	if _, err := fmt.Fprintf(w, "%-36s %-12s %14s %7s\n", "Phase", "Category", "Duration", "Boot %"); err != nil {
+		return err
+	}
+	for _, phase := range phases {
+		if _, err := fmt.Fprintf(w, "%-36s %-12s %14v %6.2f%%\n",
+			phase.Name(), HookCategory(phase.Start.HookID), phase.Duration(), phase.Percentage(total)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%-36s %-12s %14v\n", "Total", "", total)
+	return err
+}
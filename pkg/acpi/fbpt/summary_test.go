@@ -0,0 +1,47 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteSummary(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 0, "PEI"),
+		record(MODULE_END_ID, 100, "PEI"),
+		record(PERF_FUNCTION_START_ID, 100, "DXE"),
+		record(PERF_FUNCTION_END_ID, 500, "DXE"),
+	}
+	var buf bytes.Buffer
+	if err := WriteSummary(&buf, records, 1000*time.Nanosecond); err != nil {
+		t.Fatalf("WriteSummary() = %v, want nil", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("WriteSummary() wrote %d lines, want 4:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []string{"Boot %", "DXE", "PEI", "Total"} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("WriteSummary() line %d = %q, want it to contain %q", i, lines[i], want)
+		}
+	}
+	if !strings.Contains(lines[1], "40.00%") || !strings.Contains(lines[2], "10.00%") {
+		t.Errorf("WriteSummary() = \n%s\nwant DXE at 40%% and PEI at 10%%", buf.String())
+	}
+}
+
+func TestPhasePercentage(t *testing.T) {
+	phase := PhasePair{Start: record(MODULE_START_ID, 100, "PEI"), End: record(MODULE_END_ID, 350, "PEI")}
+	if got := phase.Percentage(time.Microsecond); got != 25 {
+		t.Errorf("Percentage(1µs) = %v, want 25", got)
+	}
+	if got := phase.Percentage(0); got != 0 {
+		t.Errorf("Percentage(0) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"io"
+)
+
+// tableReader serves reads within a table from a copy read in one go, so that
+// walking the records of a table in /dev/mem costs a single read instead of a
+// syscall per record. Reads outside of the table go to the underlying
+// memory.
+type tableReader struct {
+	mem   io.ReaderAt
+	addr  uint64
+	table []byte
+}
+
+// newTableReader returns a reader for mem serving the tablelength bytes at
+// addr from memory. If the table is implausibly large or cannot be read in
+// one go, e.g. because it runs past the end of mem, mem is returned as is
+// and the records are read one by one.
+func newTableReader(mem io.ReaderAt, addr uint64, tablelength uint32) io.ReaderAt {
+	if tablelength > maxPlausibleFBPTLength {
+		return mem
+	}
+	table := make([]byte, tablelength)
+	if err := readFullAt(mem, table, addr); err != nil {
+		return mem
+	}
+	return &tableReader{mem: mem, addr: addr, table: table}
+}
+
+// ReadAt implements io.ReaderAt.
+func (t *tableReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || uint64(off) < t.addr || uint64(off)-t.addr+uint64(len(p)) > uint64(len(t.table)) {
+		return t.mem.ReadAt(p, off)
+	}
+	return copy(p, t.table[uint64(off)-t.addr:]), nil
+}
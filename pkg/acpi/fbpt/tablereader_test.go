@@ -0,0 +1,88 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTableReader(t *testing.T) {
+	mem := fakeMem(dynamicRecord(MODULE_START_ID, 100, "PEI"), dynamicRecord(MODULE_END_ID, 200, "PEI"))
+	tablelength := uint32(len(mem) - tableAddr)
+	r := &countingReader{ReaderAt: bytes.NewReader(mem)}
+	tr := newTableReader(r, tableAddr, tablelength)
+	if r.calls != 1 {
+		t.Errorf("newTableReader() made %d reads, want 1", r.calls)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		off, len int
+		calls    int
+	}{
+		{name: "whole table", off: tableAddr, len: int(tablelength), calls: 1},
+		{name: "inside the table", off: tableAddr + 10, len: 20, calls: 1},
+		{name: "before the table", off: tableAddr - 4, len: 8, calls: 2},
+		{name: "past the table", off: len(mem) - 4, len: 8, calls: 3},
+	} {
+		buf := make([]byte, tt.len)
+		n, _ := tr.ReadAt(buf, int64(tt.off))
+		want := mem[tt.off:]
+		if len(want) > tt.len {
+			want = want[:tt.len]
+		}
+		if !bytes.Equal(buf[:n], want) {
+			t.Errorf("ReadAt(%s) = %x, want %x", tt.name, buf[:n], want)
+		}
+		if r.calls != tt.calls {
+			t.Errorf("ReadAt(%s) made %d reads in total, want %d", tt.name, r.calls, tt.calls)
+		}
+	}
+
+	// A table running past the end of memory is read record by record.
+	if got := newTableReader(r, tableAddr, tablelength+1); got != r {
+		t.Errorf("newTableReader(truncated table) = %T, want the underlying reader", got)
+	}
+}
+
+// BenchmarkWalk compares walking a table in a file, which like /dev/mem costs
+// a syscall per read, with and without reading the table in one go.
+func BenchmarkWalk(b *testing.B) {
+	var records [][]byte
+	for i := 0; i < maxNumberOfFBPTPerfRecords; i++ {
+		records = append(records, dynamicRecord(PERF_INMODULE_START_ID, uint64(i), fmt.Sprintf("record %d", i)))
+	}
+	mem := fakeMem(records...)
+	path := filepath.Join(b.TempDir(), "mem")
+	if err := os.WriteFile(path, mem, 0o600); err != nil {
+		b.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	tablelength := uint32(len(mem) - tableAddr)
+	nop := func(uint32, EFI_ACPI_5_0_FPDT_PERFORMANCE_RECORD_HEADER, []byte) error { return nil }
+
+	b.Run("buffered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := walk(f, tableAddr, tablelength, nop, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("unbuffered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := walkRecords(f, tableAddr, tablelength, nop, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ScaleTimestamps returns a copy of records with every timestamp multiplied
+// by nanosPerTick.
+//
+// The ACPI spec mandates that FBPT timestamps count nanoseconds, but some
+// firmware stores raw TSC (or other counter) values instead. Callers knowing
+// the counter frequency can use this to convert those to nanoseconds, e.g.
+// with nanosPerTick = 1e9 / tscHz.
+func ScaleTimestamps(records []MEASUREMENT_RECORD, nanosPerTick float64) []MEASUREMENT_RECORD {
+	scaled := make([]MEASUREMENT_RECORD, len(records))
+	for i, record := range records {
+		record.Timestamp = uint64(float64(record.Timestamp) * nanosPerTick)
+		scaled[i] = record
+	}
+	return scaled
+}
+
+// MergeTimelines combines the records of several captures, e.g. of a cold
+// boot and the following S3 resumes, into a single timeline ordered by
+// timestamp. Each record's Source is set to the index of its set.
+func MergeTimelines(sets ...[]MEASUREMENT_RECORD) []MEASUREMENT_RECORD {
+	var merged []MEASUREMENT_RECORD
+	for i, set := range sets {
+		for _, record := range set {
+			record.Source = i
+			merged = append(merged, record)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp < merged[j].Timestamp
+	})
+	return merged
+}
+
+// Reconcile checks that the records fall within the envelope of the basic
+// boot record, i.e. between ResetEnd and ExitBootServicesExit, and returns an
+// error for every record outside of it. Such records suggest either that the
+// parser drifted or that the firmware timestamps are inconsistent.
+func Reconcile(boot EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD, records []MEASUREMENT_RECORD) []error {
+	if boot.ExitBootServicesExit < boot.ResetEnd {
+		return []error{fmt.Errorf("basic boot record ExitBootServicesExit %d is before ResetEnd %d", boot.ExitBootServicesExit, boot.ResetEnd)}
+	}
+	var anomalies []error
+	for i, record := range records {
+		if record.Timestamp < boot.ResetEnd || record.Timestamp > boot.ExitBootServicesExit {
+			anomalies = append(anomalies, fmt.Errorf("record %d (%s %q) at %d is outside of the boot envelope [%d, %d]",
+				i, record.HookType, record.Description, record.Timestamp, boot.ResetEnd, boot.ExitBootServicesExit))
+		}
+	}
+	return anomalies
+}
+
+// DetectRollover returns the indices of the records whose timestamp is less
+// than the one of their predecessor. Firmware records its measurements in
+// order, so these point to a clock rollover, mixed clock sources or a
+// misparsed table.
+func DetectRollover(records []MEASUREMENT_RECORD) []int {
+	var indices []int
+	for i := 1; i < len(records); i++ {
+		if records[i].Timestamp < records[i-1].Timestamp {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// LastRecord returns the record with the greatest timestamp, the first of
+// them on a tie, and whether records has any. Its timestamp minus the
+// ResetEnd of the basic boot record is the time spent in firmware.
+func LastRecord(records []MEASUREMENT_RECORD) (MEASUREMENT_RECORD, bool) {
+	if len(records) == 0 {
+		return MEASUREMENT_RECORD{}, false
+	}
+	last := records[0]
+	for _, record := range records[1:] {
+		if record.Timestamp > last.Timestamp {
+			last = record
+		}
+	}
+	return last, true
+}
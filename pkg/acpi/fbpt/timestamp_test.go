@@ -0,0 +1,112 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScaleTimestamps(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 2000, "PEI"),
+		record(MODULE_END_ID, 5000, "PEI"),
+	}
+	// 2 GHz TSC
+	scaled := ScaleTimestamps(records, 0.5)
+	for i, want := range []uint64{1000, 2500} {
+		if scaled[i].Timestamp != want {
+			t.Errorf("scaled[%d].Timestamp = %d, want %d", i, scaled[i].Timestamp, want)
+		}
+	}
+	if records[0].Timestamp != 2000 {
+		t.Errorf("ScaleTimestamps() modified its input")
+	}
+}
+
+func TestMergeTimelines(t *testing.T) {
+	coldBoot := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 10, "PEI"),
+		record(MODULE_END_ID, 30, "PEI"),
+	}
+	resume := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 20, "S3"),
+		record(MODULE_END_ID, 30, "S3"),
+	}
+	merged := MergeTimelines(coldBoot, resume)
+
+	want := []struct {
+		description string
+		source      int
+	}{
+		{"PEI", 0},
+		{"S3", 1},
+		{"PEI", 0},
+		{"S3", 1},
+	}
+	if len(merged) != len(want) {
+		t.Fatalf("MergeTimelines() = %+v, want %d records", merged, len(want))
+	}
+	for i, w := range want {
+		if merged[i].Description != w.description || merged[i].Source != w.source {
+			t.Errorf("merged[%d] = %s from %d, want %s from %d", i, merged[i].Description, merged[i].Source, w.description, w.source)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	boot := EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{ResetEnd: 100, ExitBootServicesExit: 1000}
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 100, "PEI"),
+		record(MODULE_END_ID, 50, "early"),
+		record(MODULE_START_ID, 1000, "DXE"),
+		record(MODULE_END_ID, 1001, "late"),
+	}
+	anomalies := Reconcile(boot, records)
+	if len(anomalies) != 2 {
+		t.Fatalf("Reconcile() = %v, want 2 anomalies", anomalies)
+	}
+	for i, want := range []string{"early", "late"} {
+		if !strings.Contains(anomalies[i].Error(), want) {
+			t.Errorf("Reconcile()[%d] = %v, want it to mention %q", i, anomalies[i], want)
+		}
+	}
+
+	if anomalies := Reconcile(EFI_ACPI_6_5_FPDT_FIRMWARE_BASIC_BOOT_RECORD{ResetEnd: 100}, records); len(anomalies) != 1 {
+		t.Errorf("Reconcile() with inverted envelope = %v, want 1 error", anomalies)
+	}
+}
+
+func TestDetectRollover(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 100, "PEI"),
+		record(MODULE_END_ID, 100, "PEI"),
+		record(MODULE_START_ID, 50, "DXE"),
+		record(MODULE_END_ID, 200, "DXE"),
+		record(MODULE_START_ID, 10, "BDS"),
+	}
+	if got, want := DetectRollover(records), []int{2, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectRollover() = %v, want %v", got, want)
+	}
+	if got := DetectRollover(records[:2]); got != nil {
+		t.Errorf("DetectRollover(monotonic) = %v, want nil", got)
+	}
+}
+
+func TestLastRecord(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 100, "PEI"),
+		record(MODULE_END_ID, 300, "DXE"),
+		record(MODULE_START_ID, 300, "late"),
+		record(MODULE_END_ID, 200, "PEI"),
+	}
+	if got, ok := LastRecord(records); !ok || got.Description != "DXE" {
+		t.Errorf("LastRecord() = %+v, %t, want the DXE record, true", got, ok)
+	}
+	if got, ok := LastRecord(nil); ok || got != (MEASUREMENT_RECORD{}) {
+		t.Errorf("LastRecord(nil) = %+v, %t, want zero record, false", got, ok)
+	}
+}
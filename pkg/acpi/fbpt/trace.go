@@ -0,0 +1,51 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// traceEvent is a complete event of the Chrome trace event format, see
+// https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type traceEvent struct {
+	Name     string  `json:"name"`
+	Category string  `json:"cat"`
+	Phase    string  `json:"ph"`
+	TS       float64 `json:"ts"`
+	Dur      float64 `json:"dur"`
+	PID      int     `json:"pid"`
+	TID      uint32  `json:"tid"`
+}
+
+type traceFile struct {
+	TraceEvents     []traceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+// WriteTraceEvent writes the phases found in records as Chrome trace events,
+// which can be loaded into chrome://tracing or Perfetto next to kernel
+// traces. Record timestamps are nanoseconds relative to bootEpoch.
+func WriteTraceEvent(w io.Writer, records []MEASUREMENT_RECORD, bootEpoch time.Time) error {
+	epoch := bootEpoch.UnixNano()
+	trace := traceFile{
+		TraceEvents:     []traceEvent{},
+		DisplayTimeUnit: "ns",
+	}
+	for _, phase := range PairPhases(records) {
+		trace.TraceEvents = append(trace.TraceEvents, traceEvent{
+			Name:     phase.Name(),
+			Category: phase.Start.HookType,
+			Phase:    "X",
+			// The trace event format counts in microseconds.
+			TS:  float64(epoch+int64(phase.Start.Timestamp)) / 1e3,
+			Dur: float64(phase.Duration().Nanoseconds()) / 1e3,
+			TID: phase.Start.ProcessorIdentifier,
+		})
+	}
+	return json.NewEncoder(w).Encode(trace)
+}
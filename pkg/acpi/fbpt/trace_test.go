@@ -0,0 +1,32 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbpt
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWriteTraceEvent(t *testing.T) {
+	records := []MEASUREMENT_RECORD{
+		record(MODULE_START_ID, 2000, "PEI"),
+		record(MODULE_END_ID, 5000, "PEI"),
+	}
+	var buf bytes.Buffer
+	if err := WriteTraceEvent(&buf, records, time.Unix(1, 0)); err != nil {
+		t.Fatalf("WriteTraceEvent() = %v, want nil", err)
+	}
+
+	var got traceFile
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal() = %v, want nil", err)
+	}
+	want := traceEvent{Name: "PEI", Category: "MODULE_START_ID", Phase: "X", TS: 1000002, Dur: 3}
+	if len(got.TraceEvents) != 1 || got.TraceEvents[0] != want {
+		t.Errorf("WriteTraceEvent() = %+v, want [%+v]", got.TraceEvents, want)
+	}
+}
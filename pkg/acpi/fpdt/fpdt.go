@@ -13,6 +13,13 @@ import (
 const (
 	// ACPI FPDT table
 	acpiFPDTSig = "FPDT"
+
+	// FPDT performance pointer records
+	fbptPointerRecordType   = 0x0000
+	s3ptPointerRecordType   = 0x0001
+	pointerRecordRevision   = 1
+	pointerRecordLength     = 16
+	pointerRecordHeaderSize = 4
 )
 
 // Finds which ACPI table is FPDT and returns it
@@ -38,22 +45,50 @@ func ReadACPIFPDTTable() (acpi.Table, error) {
 
 }
 
+// FindFBPTTableAdrr returns the address of the Firmware Basic Boot
+// Performance Table from the Firmware Basic Boot Performance Pointer Record
+// of the FPDT t.
 func FindFBPTTableAdrr(t acpi.Table) (uint64, error) {
-	var addr uint64
+	// see ACPI Table Spec: https://uefi.org/sites/default/files/resources/ACPI%206_2_A_Sept29.pdf (page 210)
+	addr, found, err := findPointerRecord(t, fbptPointerRecordType)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, errors.New("FPDT has no Firmware Basic Boot Performance Pointer Record")
+	}
+	return addr, nil
+}
 
+// findPointerRecord returns the address held by the performance pointer
+// record of type recordType in the FPDT t. The record's length and revision
+// are validated so that a malformed FPDT does not yield a wild pointer.
+func findPointerRecord(t acpi.Table, recordType uint16) (uint64, bool, error) {
 	if t.Sig() != "FPDT" {
-		return addr, fmt.Errorf("Wrong table type passed. Table Signature %s", t.Sig())
+		return 0, false, fmt.Errorf("Wrong table type passed. Table Signature %s", t.Sig())
 	}
 
-	for i := 0; i < len(t.TableData()); i += int(t.TableData()[i+2]) {
-		// Find Firmware Basic Boot Performance Pointer Record
-		// see ACPI Table Spec: https://uefi.org/sites/default/files/resources/ACPI%206_2_A_Sept29.pdf (page 210)
-		if t.TableData()[i] == 0x00 && t.TableData()[i+1] == 0x00 {
-			addr = ubinary.NativeEndian.Uint64(t.TableData()[i+8 : i+16])
-			break
+	data := t.TableData()
+	for i := 0; i < len(data); {
+		if len(data)-i < pointerRecordHeaderSize {
+			return 0, false, fmt.Errorf("FPDT record at offset %d is truncated", i)
 		}
+		recordLength := int(data[i+2])
+		if recordLength < pointerRecordHeaderSize || recordLength > len(data)-i {
+			return 0, false, fmt.Errorf("FPDT record at offset %d has invalid length %d", i, recordLength)
+		}
+		if ubinary.NativeEndian.Uint16(data[i:i+2]) == recordType {
+			if revision := data[i+3]; revision != pointerRecordRevision {
+				return 0, false, fmt.Errorf("FPDT performance pointer record of type %#x has revision %d, want %d", recordType, revision, pointerRecordRevision)
+			}
+			if recordLength != pointerRecordLength {
+				return 0, false, fmt.Errorf("FPDT performance pointer record of type %#x has length %d, want %d", recordType, recordLength, pointerRecordLength)
+			}
+			return ubinary.NativeEndian.Uint64(data[i+8 : i+16]), true, nil
+		}
+		i += recordLength
 	}
-	return addr, nil
+	return 0, false, nil
 }
 
 // Reads Header for records found in FPDT Table as found in ACPI spec
@@ -76,3 +111,11 @@ func ReadFPDTRecordHeader(mem io.ReadSeeker) (uint16, uint8, uint8, error) {
 
 	return ubinary.NativeEndian.Uint16(HeaderType[:]), uint8(HeaderLength[0]), uint8(HeaderRevision[0]), nil
 }
+
+// FindS3PTTableAddr returns the address of the S3 Performance Table from the
+// S3 Performance Table Pointer Record of the FPDT, or 0 if there is none.
+func FindS3PTTableAddr(t acpi.Table) (uint64, error) {
+	// see ACPI Table Spec: https://uefi.org/sites/default/files/resources/ACPI%206_2_A_Sept29.pdf (page 211)
+	addr, _, err := findPointerRecord(t, s3ptPointerRecordType)
+	return addr, err
+}
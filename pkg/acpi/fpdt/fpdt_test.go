@@ -0,0 +1,93 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fpdt
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/acpi"
+)
+
+// pointerRecord returns an FPDT performance pointer record.
+func pointerRecord(recordType uint16, revision uint8, addr uint64) []byte {
+	b := make([]byte, pointerRecordLength)
+	binary.LittleEndian.PutUint16(b, recordType)
+	b[2] = pointerRecordLength
+	b[3] = revision
+	binary.LittleEndian.PutUint64(b[8:], addr)
+	return b
+}
+
+// fpdtTable returns an FPDT with the given records as its body.
+func fpdtTable(t *testing.T, records ...[]byte) acpi.Table {
+	t.Helper()
+	b := make([]byte, 36)
+	copy(b, acpiFPDTSig)
+	for _, r := range records {
+		b = append(b, r...)
+	}
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(b)))
+	b[8] = 1
+	var sum uint8
+	for _, c := range b {
+		sum += c
+	}
+	b[9] = -sum
+	tables, err := acpi.NewRaw(b)
+	if err != nil {
+		t.Fatalf("acpi.NewRaw() = _, %v, want nil", err)
+	}
+	return tables[0]
+}
+
+func TestFindFBPTTableAdrr(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		records [][]byte
+		want    uint64
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			records: [][]byte{pointerRecord(s3ptPointerRecordType, 1, 0x3000), pointerRecord(fbptPointerRecordType, 1, 0x2000)},
+			want:    0x2000,
+		},
+		{
+			name:    "wrong type",
+			records: [][]byte{pointerRecord(0x0002, 1, 0x2000)},
+			wantErr: "no Firmware Basic Boot Performance Pointer Record",
+		},
+		{
+			name:    "wrong revision",
+			records: [][]byte{pointerRecord(fbptPointerRecordType, 2, 0x2000)},
+			wantErr: "revision 2, want 1",
+		},
+		{
+			name:    "zero length",
+			records: [][]byte{{0x01, 0x00, 0x00, 0x01}},
+			wantErr: "invalid length 0",
+		},
+		{
+			name:    "truncated",
+			records: [][]byte{pointerRecord(fbptPointerRecordType, 1, 0x2000)[:12]},
+			wantErr: "invalid length 16",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := FindFBPTTableAdrr(fpdtTable(t, tt.records...))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("FindFBPTTableAdrr() = %#x, %v, want error containing %q", addr, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || addr != tt.want {
+				t.Fatalf("FindFBPTTableAdrr() = %#x, %v, want %#x, nil", addr, err, tt.want)
+			}
+		})
+	}
+}
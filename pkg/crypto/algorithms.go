@@ -0,0 +1,18 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+// SupportedAlgorithms returns the names of the signature algorithms the
+// package can sign or verify with, e.g. to list them in a tool's help.
+//
+//   - ed25519: sign and verify full messages, see GeneratED25519Key
+//   - rsa-pkcs1v15, ecdsa: sign and verify digests, see SignReader
+//   - rsa-pss: verify digests, see VerifyReaderWithRSAOptions
+//   - hmac: authenticate messages with a shared key, see SignHMAC
+//   - pgp: verify detached OpenPGP signatures, see VerifyPGP
+//   - jws: verify compact JSON Web Signatures, see VerifyJWS
+func SupportedAlgorithms() []string {
+	return []string{"ed25519", "rsa-pkcs1v15", "rsa-pss", "ecdsa", "hmac", "pgp", "jws"}
+}
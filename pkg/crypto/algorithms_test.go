@@ -0,0 +1,23 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import "testing"
+
+func TestSupportedAlgorithms(t *testing.T) {
+	algs := SupportedAlgorithms()
+	seen := make(map[string]bool)
+	for _, alg := range algs {
+		if seen[alg] {
+			t.Errorf(`SupportedAlgorithms() = %q, lists %q twice`, algs, alg)
+		}
+		seen[alg] = true
+	}
+	for _, want := range []string{"ed25519", "rsa-pkcs1v15", "rsa-pss", "ecdsa"} {
+		if !seen[want] {
+			t.Errorf(`SupportedAlgorithms() = %q, want it to contain %q`, algs, want)
+		}
+	}
+}
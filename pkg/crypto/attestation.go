@@ -0,0 +1,161 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// InTotoStatementType is the _type of the statements made by
+	// SignAttestation.
+	InTotoStatementType = "https://in-toto.io/Statement/v1"
+	// InTotoPayloadType is the DSSE payload type of in-toto statements.
+	InTotoPayloadType = "application/vnd.in-toto+json"
+)
+
+// AttestationPredicateType is the predicateType of the statements made by
+// SignAttestation.
+var AttestationPredicateType = "https://slsa.dev/provenance/v1"
+
+// ErrAttestationSignature is returned by VerifyAttestation if no signature of
+// the envelope verifies with the given key.
+var ErrAttestationSignature = errors.New("invalid attestation signature")
+
+// AttestationSubject is an artifact an in-toto statement is about.
+type AttestationSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// AttestationStatement is an in-toto statement.
+type AttestationStatement struct {
+	Type          string               `json:"_type"`
+	Subject       []AttestationSubject `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     json.RawMessage      `json:"predicate"`
+}
+
+type dsseSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   []byte `json:"sig"`
+}
+
+type dsseEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     []byte          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+}
+
+// dssePAE returns the DSSE pre-authentication encoding of payload, which is
+// what the signatures of an envelope are made over.
+func dssePAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// signMessage signs the full message msg like verifyMessage expects:
+// Ed25519 signs msg itself, other algorithms its SHA-256 digest.
+func signMessage(signer crypto.Signer, msg []byte) ([]byte, error) {
+	if _, ok := typedPublicKey(signer.Public()).(ed25519.PublicKey); ok {
+		return signer.Sign(nil, msg, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(msg)
+	return signer.Sign(nil, digest[:], crypto.SHA256)
+}
+
+// SignAttestation returns a DSSE envelope with an in-toto statement about
+// subject, which maps artifact names to their lower case hex SHA-256, with
+// predicate marshaled as JSON and of type AttestationPredicateType. The
+// envelope is signed with signer, see verifyMessage for the supported
+// algorithms.
+func SignAttestation(signer crypto.Signer, subject map[string]string, predicate any) ([]byte, error) {
+	if len(subject) == 0 {
+		return nil, errors.New("attestation needs at least one subject")
+	}
+	names := make([]string, 0, len(subject))
+	for name, digest := range subject {
+		if b, err := hex.DecodeString(digest); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("subject %q: %q is not a hex SHA-256 digest", name, digest)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rawPredicate, err := json.Marshal(predicate)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal predicate: %w", err)
+	}
+	statement := AttestationStatement{
+		Type:          InTotoStatementType,
+		PredicateType: AttestationPredicateType,
+		Predicate:     rawPredicate,
+	}
+	for _, name := range names {
+		statement.Subject = append(statement.Subject, AttestationSubject{
+			Name:   name,
+			Digest: map[string]string{"sha256": subject[name]},
+		})
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID, err := NormalizedKeyID(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signMessage(signer, dssePAE(InTotoPayloadType, payload))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(dsseEnvelope{
+		PayloadType: InTotoPayloadType,
+		Payload:     payload,
+		Signatures:  []dsseSignature{{KeyID: keyID, Sig: sig}},
+	})
+}
+
+// VerifyAttestation verifies a DSSE envelope as made by SignAttestation with
+// pub and returns the in-toto statement it carries. One valid signature by
+// pub is enough, signatures by other keys are ignored.
+func VerifyAttestation(envelope []byte, pub crypto.PublicKey) (*AttestationStatement, error) {
+	var env dsseEnvelope
+	if err := json.Unmarshal(envelope, &env); err != nil {
+		return nil, fmt.Errorf("can't parse DSSE envelope: %w", err)
+	}
+	if env.PayloadType != InTotoPayloadType {
+		return nil, fmt.Errorf("DSSE payload type %q, want %q", env.PayloadType, InTotoPayloadType)
+	}
+
+	pae := dssePAE(env.PayloadType, env.Payload)
+	verified := false
+	for _, s := range env.Signatures {
+		if ok, err := verifyMessage(typedPublicKey(pub), pae, s.Sig); err == nil && ok {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrAttestationSignature
+	}
+
+	var statement AttestationStatement
+	if err := json.Unmarshal(env.Payload, &statement); err != nil {
+		return nil, fmt.Errorf("can't parse in-toto statement: %w", err)
+	}
+	if statement.Type != InTotoStatementType {
+		return nil, fmt.Errorf("in-toto statement type %q, want %q", statement.Type, InTotoStatementType)
+	}
+	return &statement, nil
+}
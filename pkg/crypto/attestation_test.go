@@ -0,0 +1,85 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestAttestation(t *testing.T) {
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kernel := sha256.Sum256([]byte("bzImage"))
+	subject := map[string]string{"bzImage": hex.EncodeToString(kernel[:])}
+	predicate := map[string]string{"builder": "u-root"}
+
+	for _, tt := range []struct {
+		name string
+		priv any
+		pub  any
+	}{
+		{"ed25519", edPriv, edPub},
+		{"ecdsa", ecKey, &ecKey.PublicKey},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := SignAttestation(NewMemorySigner(tt.priv), subject, predicate)
+			if err != nil {
+				t.Fatalf(`SignAttestation() = _, %v, want nil`, err)
+			}
+			statement, err := VerifyAttestation(envelope, tt.pub)
+			if err != nil {
+				t.Fatalf(`VerifyAttestation() = _, %v, want nil`, err)
+			}
+			if len(statement.Subject) != 1 || statement.Subject[0].Name != "bzImage" || statement.Subject[0].Digest["sha256"] != subject["bzImage"] {
+				t.Errorf(`VerifyAttestation() subject = %+v, want %v`, statement.Subject, subject)
+			}
+			if statement.PredicateType != AttestationPredicateType || string(statement.Predicate) != `{"builder":"u-root"}` {
+				t.Errorf(`VerifyAttestation() predicate = %q of type %q, want {"builder":"u-root"}`, statement.Predicate, statement.PredicateType)
+			}
+
+			var env dsseEnvelope
+			if err := json.Unmarshal(envelope, &env); err != nil {
+				t.Fatal(err)
+			}
+			env.Payload[len(env.Payload)-2] = ' '
+			tampered, err := json.Marshal(env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := VerifyAttestation(tampered, tt.pub); !errors.Is(err, ErrAttestationSignature) {
+				t.Errorf(`VerifyAttestation(tampered) = _, %v, want %v`, err, ErrAttestationSignature)
+			}
+		})
+	}
+
+	envelope, err := SignAttestation(NewMemorySigner(edPriv), subject, predicate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyAttestation(envelope, &ecKey.PublicKey); !errors.Is(err, ErrAttestationSignature) {
+		t.Errorf(`VerifyAttestation(other key) = _, %v, want %v`, err, ErrAttestationSignature)
+	}
+	if _, err := SignAttestation(NewMemorySigner(edPriv), map[string]string{"bzImage": "abc"}, predicate); err == nil {
+		t.Errorf(`SignAttestation(bad digest) = _, nil, want error`)
+	}
+	if _, err := SignAttestation(NewMemorySigner(edPriv), nil, predicate); err == nil {
+		t.Errorf(`SignAttestation(no subject) = _, nil, want error`)
+	}
+}
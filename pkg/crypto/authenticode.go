@@ -0,0 +1,283 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"debug/pe"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrAuthenticodeSignature is returned by VerifyAuthenticode if the signature
+// does not match the PE image.
+var ErrAuthenticodeSignature = errors.New("authenticode signature verification failed")
+
+const (
+	// winCertTypePKCSSignedData is the WIN_CERTIFICATE type of Authenticode
+	// signatures.
+	winCertTypePKCSSignedData = 0x0002
+	// winCertificateHeaderSize is the size of dwLength, wRevision and
+	// wCertificateType.
+	winCertificateHeaderSize = 8
+	// peCertificateTableIndex is the index of the certificate table in the
+	// data directories.
+	peCertificateTableIndex = 4
+)
+
+var (
+	oidSignedData        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidSpcIndirectData   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 4}
+	oidAttributeDigest   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	authenticodeHashOIDs = map[string]crypto.Hash{
+		"2.16.840.1.101.3.4.2.1": crypto.SHA256,
+		"2.16.840.1.101.3.4.2.2": crypto.SHA384,
+		"2.16.840.1.101.3.4.2.3": crypto.SHA512,
+	}
+)
+
+// The PKCS#7 and Authenticode structures needed for verification, see RFC
+// 2315 and the Windows Authenticode PE Signature Format specification.
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	// Content is the [0] EXPLICIT wrapper, its Bytes are the DER content.
+	Content asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      pkcs7ContentInfo
+	Certificates     asn1.RawValue     `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue     `asn1:"optional,tag:1"`
+	SignerInfos      []pkcs7SignerInfo `asn1:"set"`
+}
+
+type pkcs7IssuerAndSerial struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type pkcs7SignerInfo struct {
+	Version                   int
+	IssuerAndSerialNumber     pkcs7IssuerAndSerial
+	DigestAlgorithm           pkix.AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue `asn1:"optional,tag:0"`
+	DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedDigest           []byte
+	UnauthenticatedAttributes asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type pkcs7Attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type pkcs7DigestInfo struct {
+	DigestAlgorithm pkix.AlgorithmIdentifier
+	Digest          []byte
+}
+
+type spcAttributeTypeAndOptionalValue struct {
+	Type  asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"optional"`
+}
+
+type spcIndirectDataContent struct {
+	Data          spcAttributeTypeAndOptionalValue
+	MessageDigest pkcs7DigestInfo
+}
+
+// authenticodeHash returns the Authenticode digest of the PE image b: the
+// hash of the file without its checksum, its certificate table directory
+// entry and the certificate table itself.
+func authenticodeHash(b []byte, h crypto.Hash, checksumOff, certDirOff, certOff, certSize uint32) []byte {
+	d := h.New()
+	d.Write(b[:checksumOff])
+	d.Write(b[checksumOff+4 : certDirOff])
+	d.Write(b[certDirOff+8 : certOff])
+	d.Write(b[certOff+certSize:])
+	return d.Sum(nil)
+}
+
+// peCertificateTable returns the file offsets of the checksum and the
+// certificate table directory entry of the PE image b, and the location of
+// its certificate table.
+func peCertificateTable(b []byte) (checksumOff, certDirOff uint32, dir pe.DataDirectory, err error) {
+	f, err := pe.NewFile(bytes.NewReader(b))
+	if err != nil {
+		return 0, 0, dir, err
+	}
+	// The optional header follows the "PE\0\0" signature and the COFF
+	// header, at an offset given by the DOS header.
+	optOff := binary.LittleEndian.Uint32(b[0x3c:]) + 4 + 20
+	checksumOff = optOff + 64
+	switch oh := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		if oh.NumberOfRvaAndSizes <= peCertificateTableIndex {
+			return 0, 0, dir, errors.New("PE image has no certificate table")
+		}
+		dir, certDirOff = oh.DataDirectory[peCertificateTableIndex], optOff+96+8*peCertificateTableIndex
+	case *pe.OptionalHeader64:
+		if oh.NumberOfRvaAndSizes <= peCertificateTableIndex {
+			return 0, 0, dir, errors.New("PE image has no certificate table")
+		}
+		dir, certDirOff = oh.DataDirectory[peCertificateTableIndex], optOff+112+8*peCertificateTableIndex
+	default:
+		return 0, 0, dir, errors.New("PE image has no optional header")
+	}
+	if dir.Size == 0 {
+		return 0, 0, dir, errors.New("PE image is not signed")
+	}
+	if uint64(dir.VirtualAddress)+uint64(dir.Size) > uint64(len(b)) || dir.VirtualAddress < certDirOff+8 {
+		return 0, 0, dir, fmt.Errorf("PE certificate table at %#x of %d bytes is outside of the image", dir.VirtualAddress, dir.Size)
+	}
+	return checksumOff, certDirOff, dir, nil
+}
+
+// authenticodeSignatures maps the supported signer key and digest algorithms
+// to the x509 signature algorithm.
+var authenticodeSignatures = map[x509.PublicKeyAlgorithm]map[crypto.Hash]x509.SignatureAlgorithm{
+	x509.RSA: {
+		crypto.SHA256: x509.SHA256WithRSA,
+		crypto.SHA384: x509.SHA384WithRSA,
+		crypto.SHA512: x509.SHA512WithRSA,
+	},
+	x509.ECDSA: {
+		crypto.SHA256: x509.ECDSAWithSHA256,
+		crypto.SHA384: x509.ECDSAWithSHA384,
+		crypto.SHA512: x509.ECDSAWithSHA512,
+	},
+}
+
+// VerifyAuthenticode verifies the Authenticode signature embedded in the
+// certificate table of the PE image peBytes, e.g. a signed EFI executable:
+// the image digest has to match the signed one, the signature has to be made
+// by the signer certificate and that has to chain up to roots, using the
+// other embedded certificates as intermediates. Only the first signature of
+// the table is checked. Like UEFI Secure Boot, expired certificates are
+// accepted as long as they were valid when the signer certificate was issued,
+// and revocation is not checked. SHA-256, SHA-384 and SHA-512 with RSA or
+// ECDSA keys are supported.
+func VerifyAuthenticode(peBytes []byte, roots *x509.CertPool) error {
+	checksumOff, certDirOff, dir, err := peCertificateTable(peBytes)
+	if err != nil {
+		return err
+	}
+	table := peBytes[dir.VirtualAddress : dir.VirtualAddress+dir.Size]
+	if len(table) < winCertificateHeaderSize {
+		return errors.New("PE certificate table is truncated")
+	}
+	length := binary.LittleEndian.Uint32(table)
+	if certType := binary.LittleEndian.Uint16(table[6:]); certType != winCertTypePKCSSignedData {
+		return fmt.Errorf("unsupported WIN_CERTIFICATE type %#x", certType)
+	}
+	if length < winCertificateHeaderSize || length > uint32(len(table)) {
+		return fmt.Errorf("invalid WIN_CERTIFICATE length %d", length)
+	}
+
+	var contentInfo pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(table[winCertificateHeaderSize:length], &contentInfo); err != nil {
+		return fmt.Errorf("can't parse PKCS#7 signature: %w", err)
+	}
+	if !contentInfo.ContentType.Equal(oidSignedData) {
+		return fmt.Errorf("PKCS#7 content type %v is not signed data", contentInfo.ContentType)
+	}
+	var signedData pkcs7SignedData
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		return fmt.Errorf("can't parse PKCS#7 signed data: %w", err)
+	}
+	if !signedData.ContentInfo.ContentType.Equal(oidSpcIndirectData) {
+		return fmt.Errorf("PKCS#7 content type %v is not SpcIndirectDataContent", signedData.ContentInfo.ContentType)
+	}
+	if len(signedData.SignerInfos) != 1 {
+		return fmt.Errorf("PKCS#7 signature has %d signers, want 1", len(signedData.SignerInfos))
+	}
+	signer := signedData.SignerInfos[0]
+
+	// The image digest has to match the signed content.
+	var content asn1.RawValue
+	if _, err := asn1.Unmarshal(signedData.ContentInfo.Content.Bytes, &content); err != nil {
+		return fmt.Errorf("can't parse SpcIndirectDataContent: %w", err)
+	}
+	var indirect spcIndirectDataContent
+	if _, err := asn1.Unmarshal(content.FullBytes, &indirect); err != nil {
+		return fmt.Errorf("can't parse SpcIndirectDataContent: %w", err)
+	}
+	imageHash, ok := authenticodeHashOIDs[indirect.MessageDigest.DigestAlgorithm.Algorithm.String()]
+	if !ok {
+		return fmt.Errorf("unsupported image digest algorithm %v", indirect.MessageDigest.DigestAlgorithm.Algorithm)
+	}
+	if !bytes.Equal(authenticodeHash(peBytes, imageHash, checksumOff, certDirOff, dir.VirtualAddress, dir.Size), indirect.MessageDigest.Digest) {
+		return fmt.Errorf("%w: image digest mismatch", ErrAuthenticodeSignature)
+	}
+
+	// The signed attributes have to hold the digest of the content, which
+	// for Authenticode excludes the tag and length of its SEQUENCE.
+	h, ok := authenticodeHashOIDs[signer.DigestAlgorithm.Algorithm.String()]
+	if !ok {
+		return fmt.Errorf("unsupported signer digest algorithm %v", signer.DigestAlgorithm.Algorithm)
+	}
+	if len(signer.AuthenticatedAttributes.FullBytes) == 0 {
+		return errors.New("PKCS#7 signer has no authenticated attributes")
+	}
+	var messageDigest []byte
+	for rest := signer.AuthenticatedAttributes.Bytes; len(rest) > 0; {
+		var attr pkcs7Attribute
+		if rest, err = asn1.Unmarshal(rest, &attr); err != nil {
+			return fmt.Errorf("can't parse authenticated attribute: %w", err)
+		}
+		if attr.Type.Equal(oidAttributeDigest) {
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &messageDigest); err != nil {
+				return fmt.Errorf("can't parse message digest attribute: %w", err)
+			}
+		}
+	}
+	contentDigest := h.New()
+	contentDigest.Write(content.Bytes)
+	if messageDigest == nil || !bytes.Equal(contentDigest.Sum(nil), messageDigest) {
+		return fmt.Errorf("%w: content digest mismatch", ErrAuthenticodeSignature)
+	}
+
+	// The signature covers the authenticated attributes as a DER SET.
+	certs, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	if err != nil {
+		return fmt.Errorf("can't parse PKCS#7 certificates: %w", err)
+	}
+	var signerCert *x509.Certificate
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs {
+		if cert.SerialNumber.Cmp(signer.IssuerAndSerialNumber.SerialNumber) == 0 && bytes.Equal(cert.RawIssuer, signer.IssuerAndSerialNumber.Issuer.FullBytes) {
+			signerCert = cert
+			continue
+		}
+		intermediates.AddCert(cert)
+	}
+	if signerCert == nil {
+		return errors.New("PKCS#7 signature does not include the signer certificate")
+	}
+	alg, ok := authenticodeSignatures[signerCert.PublicKeyAlgorithm][h]
+	if !ok {
+		return fmt.Errorf("unsupported signature algorithm %v with %v key", h, signerCert.PublicKeyAlgorithm)
+	}
+	signedAttrs := append([]byte{0x31}, signer.AuthenticatedAttributes.FullBytes[1:]...)
+	if err := signerCert.CheckSignature(alg, signedAttrs, signer.EncryptedDigest); err != nil {
+		return fmt.Errorf("%w: %v", ErrAuthenticodeSignature, err)
+	}
+
+	_, err = signerCert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   signerCert.NotBefore,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
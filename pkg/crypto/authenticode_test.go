@@ -0,0 +1,183 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+const (
+	// Layout of the PE32+ image built by testPE.
+	testPEOptionalHeader = 0x58
+	testPEChecksum       = testPEOptionalHeader + 64
+	testPECertDir        = testPEOptionalHeader + 112 + 8*peCertificateTableIndex
+)
+
+var (
+	oidSHA256          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidSpcPEImageData  = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 15}
+)
+
+// testPE returns a minimal unsigned PE32+ image with a single section.
+func testPE() []byte {
+	b := make([]byte, 0x400)
+	copy(b, "MZ")
+	binary.LittleEndian.PutUint32(b[0x3c:], 0x40)
+	copy(b[0x40:], "PE\x00\x00")
+	binary.LittleEndian.PutUint16(b[0x44:], 0x8664) // Machine
+	binary.LittleEndian.PutUint16(b[0x46:], 1)      // NumberOfSections
+	binary.LittleEndian.PutUint16(b[0x54:], 240)    // SizeOfOptionalHeader
+	binary.LittleEndian.PutUint16(b[0x56:], 0x22)   // Characteristics
+
+	opt := b[testPEOptionalHeader:]
+	binary.LittleEndian.PutUint16(opt[0:], 0x20b)  // Magic
+	binary.LittleEndian.PutUint32(opt[32:], 0x200) // SectionAlignment
+	binary.LittleEndian.PutUint32(opt[36:], 0x200) // FileAlignment
+	binary.LittleEndian.PutUint32(opt[56:], 0x400) // SizeOfImage
+	binary.LittleEndian.PutUint32(opt[60:], 0x200) // SizeOfHeaders
+	binary.LittleEndian.PutUint16(opt[68:], 10)    // Subsystem: EFI application
+	binary.LittleEndian.PutUint32(opt[108:], 16)   // NumberOfRvaAndSizes
+
+	section := opt[240:]
+	copy(section, ".text")
+	binary.LittleEndian.PutUint32(section[8:], 0x200)  // VirtualSize
+	binary.LittleEndian.PutUint32(section[12:], 0x200) // VirtualAddress
+	binary.LittleEndian.PutUint32(section[16:], 0x200) // SizeOfRawData
+	binary.LittleEndian.PutUint32(section[20:], 0x200) // PointerToRawData
+	copy(b[0x200:], "u-root EFI application")
+	return b
+}
+
+// signTestPE appends an Authenticode signature by leaf, including the
+// certificates of chain, to the PE image unsigned.
+func signTestPE(t *testing.T, unsigned []byte, leaf *testCert, chain ...*testCert) []byte {
+	t.Helper()
+	mustMarshal := func(v any) []byte {
+		t.Helper()
+		b, err := asn1.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	sha256Alg := pkix.AlgorithmIdentifier{Algorithm: oidSHA256}
+
+	// The certificate table is appended, so only the checksum and the
+	// directory entry have to be skipped.
+	image := sha256.New()
+	image.Write(unsigned[:testPEChecksum])
+	image.Write(unsigned[testPEChecksum+4 : testPECertDir])
+	image.Write(unsigned[testPECertDir+8:])
+	indirect := mustMarshal(spcIndirectDataContent{
+		Data:          spcAttributeTypeAndOptionalValue{Type: oidSpcPEImageData},
+		MessageDigest: pkcs7DigestInfo{DigestAlgorithm: sha256Alg, Digest: image.Sum(nil)},
+	})
+	var content asn1.RawValue
+	if _, err := asn1.Unmarshal(indirect, &content); err != nil {
+		t.Fatal(err)
+	}
+	contentDigest := sha256.Sum256(content.Bytes)
+	attrs := mustMarshal(pkcs7Attribute{
+		Type:   oidAttributeDigest,
+		Values: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: mustMarshal(contentDigest[:])},
+	})
+	attrsDigest := sha256.Sum256(mustMarshal(asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: attrs}))
+	sig, err := leaf.key.Sign(rand.Reader, attrsDigest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certs := append([]byte{}, leaf.cert.Raw...)
+	for _, c := range chain {
+		certs = append(certs, c.cert.Raw...)
+	}
+	signedData := mustMarshal(pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{sha256Alg},
+		ContentInfo: pkcs7ContentInfo{
+			ContentType: oidSpcIndirectData,
+			Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: indirect},
+		},
+		Certificates: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certs},
+		SignerInfos: []pkcs7SignerInfo{{
+			Version: 1,
+			IssuerAndSerialNumber: pkcs7IssuerAndSerial{
+				Issuer:       asn1.RawValue{FullBytes: leaf.cert.RawIssuer},
+				SerialNumber: leaf.cert.SerialNumber,
+			},
+			DigestAlgorithm:           sha256Alg,
+			AuthenticatedAttributes:   asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attrs},
+			DigestEncryptionAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256},
+			EncryptedDigest:           sig,
+		}},
+	})
+	contentInfo := mustMarshal(pkcs7ContentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+	})
+
+	winCert := make([]byte, winCertificateHeaderSize, winCertificateHeaderSize+len(contentInfo)+8)
+	binary.LittleEndian.PutUint32(winCert, uint32(winCertificateHeaderSize+len(contentInfo)))
+	binary.LittleEndian.PutUint16(winCert[4:], 0x0200)
+	binary.LittleEndian.PutUint16(winCert[6:], winCertTypePKCSSignedData)
+	winCert = append(winCert, contentInfo...)
+	for len(winCert)%8 != 0 {
+		winCert = append(winCert, 0)
+	}
+
+	signed := append(append([]byte{}, unsigned...), winCert...)
+	binary.LittleEndian.PutUint32(signed[testPECertDir:], uint32(len(unsigned)))
+	binary.LittleEndian.PutUint32(signed[testPECertDir+4:], uint32(len(winCert)))
+	return signed
+}
+
+func TestVerifyAuthenticode(t *testing.T) {
+	dir := t.TempDir()
+	root := newTestCert(t, dir, "root", true, nil)
+	intermediate := newTestCert(t, dir, "intermediate", true, root)
+	leaf := newTestCert(t, dir, "leaf", false, intermediate)
+	other := newTestCert(t, dir, "other", true, nil)
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+	otherRoots := x509.NewCertPool()
+	otherRoots.AddCert(other.cert)
+
+	signed := signTestPE(t, testPE(), leaf, intermediate)
+	if err := VerifyAuthenticode(signed, roots); err != nil {
+		t.Fatalf(`VerifyAuthenticode(signed, roots) = %v, want nil`, err)
+	}
+	// The checksum is not covered by the signature.
+	signed[testPEChecksum] ^= 0xff
+	if err := VerifyAuthenticode(signed, roots); err != nil {
+		t.Errorf(`VerifyAuthenticode(changed checksum) = %v, want nil`, err)
+	}
+
+	if err := VerifyAuthenticode(signed, otherRoots); err == nil {
+		t.Errorf(`VerifyAuthenticode(signed, otherRoots) = nil, want error`)
+	}
+	if err := VerifyAuthenticode(signTestPE(t, testPE(), leaf), roots); err == nil {
+		t.Errorf(`VerifyAuthenticode(without intermediate) = nil, want error`)
+	}
+	tampered := append([]byte{}, signed...)
+	tampered[0x200] ^= 0xff
+	if err := VerifyAuthenticode(tampered, roots); !errors.Is(err, ErrAuthenticodeSignature) {
+		t.Errorf(`VerifyAuthenticode(tampered) = %v, want %v`, err, ErrAuthenticodeSignature)
+	}
+	if err := VerifyAuthenticode(testPE(), roots); err == nil {
+		t.Errorf(`VerifyAuthenticode(unsigned) = nil, want error`)
+	}
+	if err := VerifyAuthenticode([]byte("not a PE image"), roots); err == nil {
+		t.Errorf(`VerifyAuthenticode(garbage) = nil, want error`)
+	}
+}
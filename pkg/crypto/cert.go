@@ -0,0 +1,126 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+// CertIdentifier is the PEM certificate identifier
+var CertIdentifier = "CERTIFICATE"
+
+// LoadCertificatesFromFile loads all PEM formatted X.509 certificates from file.
+func LoadCertificatesFromFile(certPath string) ([]*x509.Certificate, error) {
+	x509PEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, x509PEM = pem.Decode(x509PEM)
+		if block == nil {
+			break
+		}
+		if block.Type != CertIdentifier {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found in PEM file")
+	}
+	return certs, nil
+}
+
+// VerifyCertChain verifies that the first certificate in leafPath chains up
+// to one of the certificates in rootsPath, using the certificates in the
+// intermediates files as intermediate CAs. Any extended key usage is accepted.
+func VerifyCertChain(leafPath string, intermediates []string, rootsPath string) error {
+	leafs, err := LoadCertificatesFromFile(leafPath)
+	if err != nil {
+		return err
+	}
+
+	roots := x509.NewCertPool()
+	rootCerts, err := LoadCertificatesFromFile(rootsPath)
+	if err != nil {
+		return err
+	}
+	for _, cert := range rootCerts {
+		roots.AddCert(cert)
+	}
+
+	inters := x509.NewCertPool()
+	for _, path := range intermediates {
+		certs, err := LoadCertificatesFromFile(path)
+		if err != nil {
+			return err
+		}
+		for _, cert := range certs {
+			inters.AddCert(cert)
+		}
+	}
+
+	_, err = leafs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: inters,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// CheckKeyUsage returns an error unless cert permits all key usages in usage,
+// e.g. x509.KeyUsageDigitalSignature for a code signing certificate.
+func CheckKeyUsage(cert *x509.Certificate, usage x509.KeyUsage) error {
+	if missing := usage &^ cert.KeyUsage; missing != 0 {
+		return fmt.Errorf("certificate %q lacks key usage %#x", cert.Subject, missing)
+	}
+	return nil
+}
+
+// GenerateSelfSignedCert returns a PEM formatted self-signed certificate for
+// priv with subject as common name, valid from now on for validFor. The
+// certificate is a CA that may sign code and certificates, so that it can
+// serve both as root for VerifyCertChain and to sign artifacts directly.
+func GenerateSelfSignedCert(priv crypto.Signer, subject string, validFor time.Duration) ([]byte, error) {
+	if validFor <= 0 {
+		return nil, fmt.Errorf("invalid certificate validity %v", validFor)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: subject},
+		NotBefore:             now,
+		NotAfter:              now.Add(validFor),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, priv.Public(), priv)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: CertIdentifier, Bytes: der}), nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+	path string
+}
+
+// newTestCert creates a certificate signed by parent, or a self-signed one if
+// parent is nil, and stores it PEM encoded in dir.
+func newTestCert(t *testing.T, dir, name string, isCA bool, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	issuer, issuerKey := template, crypto.Signer(key)
+	if parent != nil {
+		issuer, issuerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := path.Join(dir, name+".pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: CertIdentifier, Bytes: der}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, path: certPath}
+}
+
+func TestLoadCertificatesFromFile(t *testing.T) {
+	if _, err := LoadCertificatesFromFile(publicKeyPEMFile); err == nil {
+		t.Errorf(`LoadCertificatesFromFile(publicKeyPEMFile) = _, nil, want error`)
+	}
+}
+
+func TestVerifyCertChain(t *testing.T) {
+	tmpdir := t.TempDir()
+	root := newTestCert(t, tmpdir, "root", true, nil)
+	inter := newTestCert(t, tmpdir, "intermediate", true, root)
+	leaf := newTestCert(t, tmpdir, "leaf", false, inter)
+	otherRoot := newTestCert(t, tmpdir, "other", true, nil)
+
+	if err := VerifyCertChain(leaf.path, []string{inter.path}, root.path); err != nil {
+		t.Errorf(`VerifyCertChain(leaf, [intermediate], root) = %v, want nil`, err)
+	}
+	if err := VerifyCertChain(leaf.path, nil, root.path); err == nil {
+		t.Errorf(`VerifyCertChain(leaf, nil, root) = nil, want error`)
+	}
+	if err := VerifyCertChain(leaf.path, []string{inter.path}, otherRoot.path); err == nil {
+		t.Errorf(`VerifyCertChain(leaf, [intermediate], other) = nil, want error`)
+	}
+}
+
+func TestCheckKeyUsage(t *testing.T) {
+	leaf := newTestCert(t, t.TempDir(), "leaf", false, nil)
+	if err := CheckKeyUsage(leaf.cert, x509.KeyUsageDigitalSignature); err != nil {
+		t.Errorf(`CheckKeyUsage(leaf, x509.KeyUsageDigitalSignature) = %v, want nil`, err)
+	}
+	if err := CheckKeyUsage(leaf.cert, x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment); err == nil {
+		t.Errorf(`CheckKeyUsage(leaf, x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment) = nil, want error`)
+	}
+}
+
+func TestGenerateSelfSignedCert(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, err := GenerateSelfSignedCert(edKey, "u-root node", time.Hour)
+	if err != nil {
+		t.Fatalf(`GenerateSelfSignedCert() = _, %v, want nil`, err)
+	}
+	certPath := path.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(certPath, certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	certs, err := LoadCertificatesFromFile(certPath)
+	if err != nil {
+		t.Fatalf(`LoadCertificatesFromFile() = _, %v, want nil`, err)
+	}
+	if got := certs[0].Subject.CommonName; got != "u-root node" {
+		t.Errorf(`certificate subject = %q, want "u-root node"`, got)
+	}
+	if !KeyPairMatches(edKey, certs[0].PublicKey) {
+		t.Errorf(`certificate public key does not match the signing key`)
+	}
+	if err := VerifyCertChain(certPath, nil, certPath); err != nil {
+		t.Errorf(`VerifyCertChain(self-signed) = %v, want nil`, err)
+	}
+
+	if _, err := GenerateSelfSignedCert(edKey, "u-root node", 0); err == nil {
+		t.Errorf(`GenerateSelfSignedCert(validFor 0) = _, nil, want error`)
+	}
+}
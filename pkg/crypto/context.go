@@ -0,0 +1,76 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// maxContextLength is the longest context Ed25519ctx supports, applied to
+// all algorithms alike.
+const maxContextLength = 255
+
+// checkContext rejects contexts that Ed25519ctx cannot carry and contexts
+// with the NUL separator, which would make the prefix ambiguous.
+func checkContext(context string) error {
+	if len(context) > maxContextLength {
+		return fmt.Errorf("signature context of %d bytes, at most %d are supported", len(context), maxContextLength)
+	}
+	if bytes.IndexByte([]byte(context), 0) >= 0 {
+		return fmt.Errorf("signature context %q contains a NUL byte", context)
+	}
+	return nil
+}
+
+// contextMessage returns msg prefixed with context and a NUL separator, which
+// is what signatures with a context are made over for algorithms without
+// native context support.
+func contextMessage(context string, msg []byte) []byte {
+	return append(append([]byte(context), 0), msg...)
+}
+
+// SignWithContext signs the full message msg bound to context, e.g.
+// "u-root-boot", so that the signature does not verify for the same message
+// in another protocol. Ed25519 keys sign with Ed25519ctx, other keys sign the
+// SHA-256 digest of context, a NUL byte and msg. Without context it signs
+// like signMessage.
+func SignWithContext(signer crypto.Signer, msg []byte, context string) ([]byte, error) {
+	if context == "" {
+		return signMessage(signer, msg)
+	}
+	if err := checkContext(context); err != nil {
+		return nil, err
+	}
+	if _, ok := typedPublicKey(signer.Public()).(ed25519.PublicKey); ok {
+		return signEd25519ctx(signer, msg, context)
+	}
+	digest := sha256.Sum256(contextMessage(context, msg))
+	return signer.Sign(nil, digest[:], crypto.SHA256)
+}
+
+// VerifyWithContext verifies sig made by SignWithContext over msg with
+// context.
+func VerifyWithContext(pub crypto.PublicKey, msg, sig []byte, context string) (bool, error) {
+	pub = typedPublicKey(pub)
+	if context == "" {
+		return verifyMessage(pub, msg, sig)
+	}
+	if err := checkContext(context); err != nil {
+		return false, err
+	}
+	key, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return verifyMessage(pub, contextMessage(context, msg), sig)
+	}
+	if err := checkSignatureLength(key, sig); err != nil {
+		return false, err
+	}
+	return verifyEd25519ctx(key, msg, sig, context)
+}
@@ -0,0 +1,26 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.20
+
+package crypto
+
+import (
+	"crypto"
+	"errors"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// errNoEd25519ctx is returned for Ed25519 signatures with a context, which
+// crypto/ed25519 only supports from Go 1.20 on.
+var errNoEd25519ctx = errors.New("Ed25519ctx requires Go 1.20 or later")
+
+func signEd25519ctx(crypto.Signer, []byte, string) ([]byte, error) {
+	return nil, errNoEd25519ctx
+}
+
+func verifyEd25519ctx(ed25519.PublicKey, []byte, []byte, string) (bool, error) {
+	return false, errNoEd25519ctx
+}
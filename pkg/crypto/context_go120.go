@@ -0,0 +1,20 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.20
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ed25519"
+)
+
+func signEd25519ctx(signer crypto.Signer, msg []byte, context string) ([]byte, error) {
+	return signer.Sign(nil, msg, &ed25519.Options{Context: context})
+}
+
+func verifyEd25519ctx(pub ed25519.PublicKey, msg, sig []byte, context string) (bool, error) {
+	return ed25519.VerifyWithOptions(pub, msg, sig, &ed25519.Options{Context: context}) == nil, nil
+}
@@ -0,0 +1,22 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.20
+
+package crypto
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestSignVerifyWithContextEd25519(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSignVerifyWithContext(t, priv)
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+)
+
+func testSignVerifyWithContext(t *testing.T, signer crypto.Signer) {
+	t.Helper()
+	msg := []byte("kernel")
+	sig, err := SignWithContext(signer, msg, "u-root-boot")
+	if err != nil {
+		t.Fatalf(`SignWithContext() = _, %v, want nil`, err)
+	}
+	pub := signer.Public()
+	if ok, err := VerifyWithContext(pub, msg, sig, "u-root-boot"); !ok || err != nil {
+		t.Errorf(`VerifyWithContext() = %t, %v, want true, nil`, ok, err)
+	}
+	if ok, _ := VerifyWithContext(pub, msg, sig, "u-root-update"); ok {
+		t.Errorf(`VerifyWithContext(other context) = true, want false`)
+	}
+	if ok, _ := VerifyWithContext(pub, msg, sig, ""); ok {
+		t.Errorf(`VerifyWithContext(no context) = true, want false`)
+	}
+
+	plain, err := SignWithContext(signer, msg, "")
+	if err != nil {
+		t.Fatalf(`SignWithContext(no context) = _, %v, want nil`, err)
+	}
+	if ok, err := verifyMessage(typedPublicKey(pub), msg, plain); !ok || err != nil {
+		t.Errorf(`verifyMessage(no context signature) = %t, %v, want true, nil`, ok, err)
+	}
+	if ok, _ := VerifyWithContext(pub, msg, plain, "u-root-boot"); ok {
+		t.Errorf(`VerifyWithContext(no context signature) = true, want false`)
+	}
+}
+
+func TestSignVerifyWithContext(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("rsa", func(t *testing.T) { testSignVerifyWithContext(t, rsaKey) })
+	t.Run("ecdsa", func(t *testing.T) { testSignVerifyWithContext(t, ecKey) })
+
+	// The separator keeps the context from bleeding into the message.
+	sig, err := SignWithContext(ecKey, []byte("boot"), "u-root")
+	if err != nil {
+		t.Fatalf(`SignWithContext() = _, %v, want nil`, err)
+	}
+	if ok, _ := VerifyWithContext(ecKey.Public(), []byte("ot"), sig, "u-rootbo"); ok {
+		t.Errorf(`VerifyWithContext(shifted context) = true, want false`)
+	}
+
+	for _, context := range []string{"u-root\x00boot", strings.Repeat("x", 256)} {
+		if _, err := SignWithContext(ecKey, []byte("boot"), context); err == nil {
+			t.Errorf(`SignWithContext(%q) = _, nil, want error`, context)
+		}
+	}
+}
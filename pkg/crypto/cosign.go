@@ -0,0 +1,114 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrCosignSignature is returned by VerifyCosignBundle if the bundle's
+// signature does not verify.
+var ErrCosignSignature = errors.New("cosign bundle signature verification failed")
+
+// sigstoreBundle is the subset of a sigstore bundle, as written by
+// "cosign sign-blob --bundle", needed to verify a message signature.
+type sigstoreBundle struct {
+	VerificationMaterial struct {
+		// Bundles up to version 0.2 carry a chain, later ones the leaf only.
+		X509CertificateChain struct {
+			Certificates []struct {
+				RawBytes []byte `json:"rawBytes"`
+			} `json:"certificates"`
+		} `json:"x509CertificateChain"`
+		Certificate struct {
+			RawBytes []byte `json:"rawBytes"`
+		} `json:"certificate"`
+	} `json:"verificationMaterial"`
+	MessageSignature struct {
+		MessageDigest struct {
+			Algorithm string `json:"algorithm"`
+			Digest    []byte `json:"digest"`
+		} `json:"messageDigest"`
+		Signature []byte `json:"signature"`
+	} `json:"messageSignature"`
+}
+
+// VerifyCosignBundle verifies the sigstore bundle at bundlePath for the file
+// at dataPath: the bundle's certificate has to chain up to roots for code
+// signing, and its key has to have signed the file.
+//
+// The transparency log entries of the bundle are not verified. Without them
+// the certificate is checked against the current time, so this only works
+// for bundles signed with long lived certificates, not with the short lived
+// certificates issued by Fulcio for keyless signing.
+func VerifyCosignBundle(bundlePath, dataPath string, roots *x509.CertPool) error {
+	b, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return err
+	}
+	var bundle sigstoreBundle
+	if err := json.Unmarshal(b, &bundle); err != nil {
+		return fmt.Errorf("can't parse cosign bundle: %w", err)
+	}
+
+	var ders [][]byte
+	for _, cert := range bundle.VerificationMaterial.X509CertificateChain.Certificates {
+		ders = append(ders, cert.RawBytes)
+	}
+	if raw := bundle.VerificationMaterial.Certificate.RawBytes; len(raw) > 0 {
+		ders = append([][]byte{raw}, ders...)
+	}
+	if len(ders) == 0 {
+		return errors.New("cosign bundle has no certificate")
+	}
+	var leaf *x509.Certificate
+	intermediates := x509.NewCertPool()
+	for i, der := range ders {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("can't parse cosign bundle certificate: %w", err)
+		}
+		if i == 0 {
+			leaf = cert
+		} else {
+			intermediates.AddCert(cert)
+		}
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(dataPath)
+	if err != nil {
+		return err
+	}
+	if digest := bundle.MessageSignature.MessageDigest; len(digest.Digest) > 0 {
+		if digest.Algorithm != "SHA2_256" {
+			return fmt.Errorf("unsupported cosign bundle digest algorithm %q", digest.Algorithm)
+		}
+		if sum := sha256.Sum256(data); !bytes.Equal(sum[:], digest.Digest) {
+			return fmt.Errorf("%w: digest of %q does not match the bundle", ErrCosignSignature, dataPath)
+		}
+	}
+
+	ok, err := verifyMessage(leaf.PublicKey, data, bundle.MessageSignature.Signature)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCosignSignature
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"testing"
+)
+
+// writeCosignBundle writes a sigstore bundle signing data with leaf.
+func writeCosignBundle(t *testing.T, bundlePath string, leaf *testCert, chain []*testCert, data []byte) {
+	t.Helper()
+	sig, err := SignReader(leaf.key, bytes.NewReader(data), crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bundle sigstoreBundle
+	for _, cert := range append([]*testCert{leaf}, chain...) {
+		bundle.VerificationMaterial.X509CertificateChain.Certificates = append(bundle.VerificationMaterial.X509CertificateChain.Certificates, struct {
+			RawBytes []byte `json:"rawBytes"`
+		}{cert.cert.Raw})
+	}
+	sum := sha256.Sum256(data)
+	bundle.MessageSignature.MessageDigest.Algorithm = "SHA2_256"
+	bundle.MessageSignature.MessageDigest.Digest = sum[:]
+	bundle.MessageSignature.Signature = sig
+	b, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bundlePath, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyCosignBundle(t *testing.T) {
+	tmpdir := t.TempDir()
+	root := newTestCert(t, tmpdir, "root", true, nil)
+	inter := newTestCert(t, tmpdir, "intermediate", true, root)
+	leaf := newTestCert(t, tmpdir, "leaf", false, inter)
+	otherRoot := newTestCert(t, tmpdir, "other", true, nil)
+
+	data := []byte("boot artifact")
+	dataPath := path.Join(tmpdir, "artifact")
+	if err := os.WriteFile(dataPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bundlePath := path.Join(tmpdir, "artifact.bundle")
+	writeCosignBundle(t, bundlePath, leaf, []*testCert{inter}, data)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+	if err := VerifyCosignBundle(bundlePath, dataPath, roots); err != nil {
+		t.Errorf(`VerifyCosignBundle() = %v, want nil`, err)
+	}
+
+	otherRoots := x509.NewCertPool()
+	otherRoots.AddCert(otherRoot.cert)
+	if err := VerifyCosignBundle(bundlePath, dataPath, otherRoots); err == nil {
+		t.Errorf(`VerifyCosignBundle(otherRoots) = nil, want error`)
+	}
+
+	tamperedPath := path.Join(tmpdir, "tampered")
+	if err := os.WriteFile(tamperedPath, []byte("tampered artifact"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyCosignBundle(bundlePath, tamperedPath, roots); !errors.Is(err, ErrCosignSignature) {
+		t.Errorf(`VerifyCosignBundle(tampered) = %v, want %v`, err, ErrCosignSignature)
+	}
+
+	if err := VerifyCosignBundle(dataPath, dataPath, roots); err == nil {
+		t.Errorf(`VerifyCosignBundle(not a bundle) = nil, want error`)
+	}
+}
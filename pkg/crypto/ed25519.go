@@ -5,11 +5,16 @@
 package crypto
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/rand"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"golang.org/x/crypto/ed25519"
 )
@@ -25,11 +30,28 @@ var (
 	PubKeyFilePermissions os.FileMode = 0o644
 	// PrivKeyFilePermissions are the private key file perms
 	PrivKeyFilePermissions os.FileMode = 0o600
+	// PubKeyFingerprintHeader is the PEM header holding the public key fingerprint
+	PubKeyFingerprintHeader = "SHA256-Fingerprint"
 )
 
+// ErrWrongPassphrase is returned by the private key loaders if an encrypted
+// key cannot be decrypted with the given passphrase. It is
+// x509.IncorrectPasswordError, so existing checks for that keep working.
+var ErrWrongPassphrase = x509.IncorrectPasswordError
+
 // LoadPublicKeyFromFile loads PEM formatted ED25519 public key from file.
 func LoadPublicKeyFromFile(publicKeyPath string) ([]byte, error) {
-	x509PEM, err := os.ReadFile(publicKeyPath)
+	f, err := os.Open(publicKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadPublicKey(f)
+}
+
+// LoadPublicKey loads PEM formatted ED25519 public key from r.
+func LoadPublicKey(r io.Reader) ([]byte, error) {
+	x509PEM, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
@@ -49,17 +71,129 @@ func LoadPublicKeyFromFile(publicKeyPath string) ([]byte, error) {
 	return block.Bytes, nil
 }
 
+// ErrKeyFileSignature is returned by LoadPublicKeyVerified if the signature
+// of the key file does not verify.
+var ErrKeyFileSignature = errors.New("public key file signature verification failed")
+
+// LoadPublicKeyVerified loads a PEM formatted public key from publicKeyPath
+// after verifying the raw detached signature in sigPath over the unmodified
+// file with trustPub, see verifyMessage for the supported algorithms. This
+// way a swapped key file is rejected before it is parsed. ED25519 keys are
+// returned as ed25519.PublicKey, other keys as parsed by crypto/x509.
+func LoadPublicKeyVerified(publicKeyPath, sigPath string, trustPub crypto.PublicKey) (crypto.PublicKey, error) {
+	keyFile, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := verifyMessage(typedPublicKey(trustPub), keyFile, sig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyFileSignature, err)
+	}
+	if !ok {
+		return nil, ErrKeyFileSignature
+	}
+
+	der, err := LoadPublicKey(bytes.NewReader(keyFile))
+	if err != nil {
+		return nil, err
+	}
+	if len(der) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(der), nil
+	}
+	return x509.ParsePKIXPublicKey(der)
+}
+
 // LoadPrivateKeyFromFile loads PEM formatted ED25519 private key from file.
 func LoadPrivateKeyFromFile(privateKeyPath string, password []byte) ([]byte, error) {
-	x509PEM, err := os.ReadFile(privateKeyPath)
+	f, err := os.Open(privateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadPrivateKey(f, password)
+}
+
+// LoadPrivateKeyWithKeyFile loads PEM formatted ED25519 private key from file,
+// decrypting it with the contents of keyFilePath as password. Like with
+// cryptsetup key files, the contents are used as is, including any trailing
+// newline.
+func LoadPrivateKeyWithKeyFile(privateKeyPath, keyFilePath string) ([]byte, error) {
+	password, err := os.ReadFile(keyFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return LoadPrivateKeyFromFile(privateKeyPath, password)
+}
+
+// LoadPrivateKeyFromFilePassphraseFD loads PEM formatted ED25519 private key
+// from file, decrypting it with the passphrase read from the file descriptor
+// fd, as passed by init systems that hand out credentials on numbered file
+// descriptors. fd is read until EOF and closed. Like with
+// LoadPrivateKeyWithKeyFile, the passphrase is used as is.
+func LoadPrivateKeyFromFilePassphraseFD(privateKeyPath string, fd int) ([]byte, error) {
+	f := os.NewFile(uintptr(fd), "passphrase")
+	if f == nil {
+		return nil, fmt.Errorf("invalid passphrase file descriptor %d", fd)
+	}
+	password, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading passphrase from file descriptor %d: %w", fd, err)
+	}
+	return LoadPrivateKeyFromFile(privateKeyPath, password)
+}
+
+// LoadPrivateKeyInteractive loads the private key at privateKeyPath like
+// LoadKeyPair, asking prompt for the passphrase. If the passphrase is wrong,
+// prompt is called again, up to maxAttempts times in total, after which
+// ErrWrongPassphrase is returned. Errors from prompt are returned right
+// away. Each passphrase is zeroed once it has been tried.
+//
+// A wrong passphrase is only detected by the padding of the decrypted key,
+// which a wrong passphrase passes about once in 256 tries; the garbage key
+// then fails to parse with a different error and is not retried.
+func LoadPrivateKeyInteractive(privateKeyPath string, maxAttempts int, prompt func() ([]byte, error)) (crypto.PrivateKey, error) {
+	if maxAttempts < 1 {
+		return nil, fmt.Errorf("invalid number of passphrase attempts %d", maxAttempts)
+	}
+	for attempt := 1; ; attempt++ {
+		password, err := prompt()
+		if err != nil {
+			return nil, err
+		}
+		priv, _, err := LoadKeyPair(privateKeyPath, password)
+		for i := range password {
+			password[i] = 0
+		}
+		if !errors.Is(err, ErrWrongPassphrase) {
+			return priv, err
+		}
+		if attempt == maxAttempts {
+			return nil, fmt.Errorf("%w after %d attempts", err, attempt)
+		}
+	}
+}
+
+// LoadPrivateKey loads PEM formatted ED25519 private key from r. Input without
+// any PEM armor is parsed as unencrypted PKCS#8 or SEC1 DER instead, see
+// loadDERPrivateKey.
+func LoadPrivateKey(r io.Reader, password []byte) ([]byte, error) {
+	x509PEM, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
 
 	// Parse x509 PEM file
 	var block *pem.Block
-	for {
+	for armored := false; ; armored = true {
 		block, x509PEM = pem.Decode(x509PEM)
+		if block == nil && !armored {
+			return loadDERPrivateKey(x509PEM)
+		}
 		if block == nil {
 			return nil, errors.New("can't decode PEM file")
 		}
@@ -68,9 +202,12 @@ func LoadPrivateKeyFromFile(privateKeyPath string, password []byte) ([]byte, err
 		}
 	}
 
-	// Check for encrypted PEM format
+	// Check for encrypted PEM format, a password given for an unencrypted
+	// key is ignored. The passphrase is never compared against anything,
+	// a wrong one only shows in the padding, which decryptPEMBlock checks
+	// in constant time.
 	if x509.IsEncryptedPEMBlock(block) {
-		decryptedKey, err := x509.DecryptPEMBlock(block, password)
+		decryptedKey, err := decryptPEMBlock(block, password)
 		if err != nil {
 			return nil, err
 		}
@@ -80,9 +217,55 @@ func LoadPrivateKeyFromFile(privateKeyPath string, password []byte) ([]byte, err
 	return block.Bytes, nil
 }
 
+// loadDERPrivateKey parses an unencrypted PKCS#8 or SEC1 DER private key, as
+// exported by some HSMs. Like for PEM files, ED25519 keys are returned as raw
+// 64 byte keys and all other keys as their DER encoding.
+func loadDERPrivateKey(der []byte) ([]byte, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		if edKey, ok := key.(ed25519.PrivateKey); ok {
+			return edKey, nil
+		}
+		return der, nil
+	}
+	if _, err := x509.ParseECPrivateKey(der); err == nil {
+		return der, nil
+	}
+	return nil, errors.New("can't decode PEM file or parse DER private key")
+}
+
+// KeyGenOptions select optional behavior of GenerateED25519KeyWithOptions.
+// The zero value generates a plain key pair like GeneratED25519Key.
+type KeyGenOptions struct {
+	// Rand is the source of the key material and the encryption IV,
+	// crypto/rand if nil
+	Rand io.Reader
+	// EmbedFingerprint adds the public key fingerprint as
+	// PubKeyFingerprintHeader PEM header
+	EmbedFingerprint bool
+	// WriteKeyInfo writes a KeyInfo sidecar next to the public key
+	WriteKeyInfo bool
+}
+
 // GeneratED25519Key generates a ED25519 keypair
 func GeneratED25519Key(password []byte, privateKeyFilePath string, publicKeyFilePath string) error {
-	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	return GenerateED25519KeyWithOptions(password, privateKeyFilePath, publicKeyFilePath, KeyGenOptions{})
+}
+
+// GenerateED25519KeyWithRand is like GeneratED25519Key but takes the key
+// material and the encryption IV from rand, so that tests can generate known
+// keys with a deterministic reader.
+func GenerateED25519KeyWithRand(rand io.Reader, password []byte, privateKeyFilePath string, publicKeyFilePath string) error {
+	return GenerateED25519KeyWithOptions(password, privateKeyFilePath, publicKeyFilePath, KeyGenOptions{Rand: rand})
+}
+
+// GenerateED25519KeyWithOptions is like GeneratED25519Key with the optional
+// behavior selected by opts.
+func GenerateED25519KeyWithOptions(password []byte, privateKeyFilePath string, publicKeyFilePath string, opts KeyGenOptions) error {
+	random := opts.Rand
+	if random == nil {
+		random = rand.Reader
+	}
+	pubKey, privKey, err := ed25519.GenerateKey(random)
 	if err != nil {
 		return err
 	}
@@ -96,10 +279,15 @@ func GeneratED25519Key(password []byte, privateKeyFilePath string, publicKeyFile
 		Type:  "PUBLIC KEY",
 		Bytes: pubKey,
 	}
+	if opts.EmbedFingerprint {
+		pubBlock.Headers = map[string]string{
+			PubKeyFingerprintHeader: Fingerprint(pubKey),
+		}
+	}
 
 	var privateKey []byte
-	if len(password) < 1 {
-		encrypted, err := x509.EncryptPEMBlock(rand.Reader, privBlock.Type, privBlock.Bytes, password, PEMCipher)
+	if len(password) > 0 {
+		encrypted, err := x509.EncryptPEMBlock(random, privBlock.Type, privBlock.Bytes, password, PEMCipher)
 		if err != nil {
 			return err
 		}
@@ -112,5 +300,40 @@ func GeneratED25519Key(password []byte, privateKeyFilePath string, publicKeyFile
 		return err
 	}
 
-	return os.WriteFile(publicKeyFilePath, pem.EncodeToMemory(pubBlock), PubKeyFilePermissions)
+	if err := os.WriteFile(publicKeyFilePath, pem.EncodeToMemory(pubBlock), PubKeyFilePermissions); err != nil {
+		return err
+	}
+
+	if opts.WriteKeyInfo {
+		return writeKeyInfo(publicKeyFilePath, KeyInfo{
+			Algorithm:   "ed25519",
+			Created:     time.Now().UTC(),
+			Fingerprint: Fingerprint(pubKey),
+		})
+	}
+	return nil
+}
+
+// Ed25519ToRaw returns the 32 byte seed of an ED25519 private key, as used by
+// tools exchanging bare key material.
+func Ed25519ToRaw(priv ed25519.PrivateKey) (seed []byte) {
+	return priv.Seed()
+}
+
+// Ed25519FromRaw reconstructs an ED25519 private key from its 32 byte seed.
+// A full 64 byte private key is accepted as well if its public half matches
+// its seed.
+func Ed25519FromRaw(seed []byte) (ed25519.PrivateKey, error) {
+	switch len(seed) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(seed), nil
+	case ed25519.PrivateKeySize:
+		priv := ed25519.NewKeyFromSeed(seed[:ed25519.SeedSize])
+		if !bytes.Equal(priv, seed) {
+			return nil, errors.New("ED25519 public key does not match seed")
+		}
+		return priv, nil
+	default:
+		return nil, fmt.Errorf("invalid ED25519 seed length %d, want %d", len(seed), ed25519.SeedSize)
+	}
 }
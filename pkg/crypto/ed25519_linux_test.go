@@ -0,0 +1,43 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+// passphraseFD returns a file descriptor to read passphrase from. It is owned
+// by the caller, unlike the descriptors of an os.File.
+func passphraseFD(t *testing.T, passphrase []byte) int {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := w.Write(passphrase); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	fd, err := syscall.Dup(int(r.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd
+}
+
+func TestLoadPrivateKeyFromFilePassphraseFD(t *testing.T) {
+	if _, err := LoadPrivateKeyFromFilePassphraseFD(privateKeyPEMFile, passphraseFD(t, password)); err != nil {
+		t.Errorf(`LoadPrivateKeyFromFilePassphraseFD(privateKeyPEMFile, fd) = _, %v, want nil`, err)
+	}
+	if _, err := LoadPrivateKeyFromFilePassphraseFD(privateKeyPEMFile, passphraseFD(t, []byte("wrong"))); err == nil {
+		t.Errorf(`LoadPrivateKeyFromFilePassphraseFD(privateKeyPEMFile, wrong passphrase fd) = _, nil, want error`)
+	}
+	if _, err := LoadPrivateKeyFromFilePassphraseFD(privateKeyPEMFile, -1); err == nil {
+		t.Errorf(`LoadPrivateKeyFromFilePassphraseFD(privateKeyPEMFile, -1) = _, nil, want error`)
+	}
+}
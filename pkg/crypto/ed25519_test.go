@@ -5,6 +5,10 @@
 package crypto
 
 import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"os"
 	"path"
 	"testing"
@@ -48,12 +52,157 @@ func TestLoadPEMPrivateKey(t *testing.T) {
 	}
 }
 
+func TestLoadPrivateKeyWithKeyFile(t *testing.T) {
+	tmpdir := t.TempDir()
+	keyFile := path.Join(tmpdir, "keyfile")
+	if err := os.WriteFile(keyFile, password, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPrivateKeyWithKeyFile(privateKeyPEMFile, keyFile); err != nil {
+		t.Errorf(`LoadPrivateKeyWithKeyFile(privateKeyPEMFile, keyFile) = _, %v, want nil`, err)
+	}
+
+	badKeyFile := path.Join(tmpdir, "badkeyfile")
+	if err := os.WriteFile(badKeyFile, append(password, '\n'), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPrivateKeyWithKeyFile(privateKeyPEMFile, badKeyFile); err == nil {
+		t.Errorf(`LoadPrivateKeyWithKeyFile(privateKeyPEMFile, badKeyFile) = _, nil, want error`)
+	}
+	if _, err := LoadPrivateKeyWithKeyFile(privateKeyPEMFile, path.Join(tmpdir, "missing")); err == nil {
+		t.Errorf(`LoadPrivateKeyWithKeyFile(privateKeyPEMFile, missing) = _, nil, want error`)
+	}
+}
+
+func TestLoadPrivateKeyInteractive(t *testing.T) {
+	// prompter returns a prompt answering with answers in turn and counting
+	// its calls in calls.
+	prompter := func(calls *int, answers ...string) func() ([]byte, error) {
+		return func() ([]byte, error) {
+			*calls++
+			if *calls > len(answers) {
+				return nil, errors.New("no more answers")
+			}
+			return []byte(answers[*calls-1]), nil
+		}
+	}
+
+	var calls int
+	priv, err := LoadPrivateKeyInteractive(privateKeyPEMFile, 3, prompter(&calls, "wrong", "kein", string(password)))
+	if err != nil {
+		t.Fatalf(`LoadPrivateKeyInteractive(privateKeyPEMFile, 3, ...) = _, %v, want nil`, err)
+	}
+	if _, ok := priv.(ed25519.PrivateKey); !ok || calls != 3 {
+		t.Errorf(`LoadPrivateKeyInteractive(privateKeyPEMFile, 3, ...) = %T after %d prompts, want ed25519.PrivateKey after 3`, priv, calls)
+	}
+
+	calls = 0
+	if _, err := LoadPrivateKeyInteractive(privateKeyPEMFile, 2, prompter(&calls, "wrong", "kein", string(password))); !errors.Is(err, ErrWrongPassphrase) || calls != 2 {
+		t.Errorf(`LoadPrivateKeyInteractive(privateKeyPEMFile, 2, ...) = _, %v after %d prompts, want %v after 2`, err, calls, ErrWrongPassphrase)
+	}
+
+	calls = 0
+	if _, err := LoadPrivateKeyInteractive(privateKeyPEMFile, 3, prompter(&calls)); err == nil || errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf(`LoadPrivateKeyInteractive(failing prompt) = _, %v, want prompt error`, err)
+	}
+	if _, err := LoadPrivateKeyInteractive(privateKeyPEMFile, 0, prompter(&calls)); err == nil {
+		t.Errorf(`LoadPrivateKeyInteractive(privateKeyPEMFile, 0, ...) = _, nil, want error`)
+	}
+}
+
+func TestLoadKeysFromReader(t *testing.T) {
+	publicKeyPEM, err := os.ReadFile(publicKeyPEMFile)
+	if err != nil {
+		t.Fatalf(`os.ReadFile(publicKeyPEMFile) = _, %v, want nil`, err)
+	}
+	if _, err := LoadPublicKey(bytes.NewReader(publicKeyPEM)); err != nil {
+		t.Errorf(`LoadPublicKey(publicKeyPEM) = _, %v, want nil`, err)
+	}
+
+	privateKeyPEM, err := os.ReadFile(privateKeyPEMFile)
+	if err != nil {
+		t.Fatalf(`os.ReadFile(privateKeyPEMFile) = _, %v, want nil`, err)
+	}
+	if _, err := LoadPrivateKey(bytes.NewReader(privateKeyPEM), password); err != nil {
+		t.Errorf(`LoadPrivateKey(privateKeyPEM, password) = _, %v, want nil`, err)
+	}
+	if _, err := LoadPrivateKey(bytes.NewReader(publicKeyPEM), password); err == nil {
+		t.Errorf(`LoadPrivateKey(publicKeyPEM, password) = _, nil, want error`)
+	}
+}
+
+func TestLoadDERPrivateKey(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, ed25519.SeedSize)
+	want := ed25519.NewKeyFromSeed(seed)
+	der, err := x509.MarshalPKCS8PrivateKey(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyPath := path.Join(t.TempDir(), "private_key.der")
+	if err := os.WriteFile(privateKeyPath, der, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	privateKey, err := LoadPrivateKeyFromFile(privateKeyPath, nil)
+	if err != nil {
+		t.Fatalf(`LoadPrivateKeyFromFile(privateKeyPath, nil) = _, %v, want nil`, err)
+	}
+	if !bytes.Equal(privateKey, want) {
+		t.Errorf(`LoadPrivateKeyFromFile(privateKeyPath, nil) = %x, want %x`, privateKey, []byte(want))
+	}
+
+	if _, err := LoadPrivateKey(bytes.NewReader([]byte("neither PEM nor DER")), nil); err == nil {
+		t.Errorf(`LoadPrivateKey("neither PEM nor DER") = _, nil, want error`)
+	}
+}
+
 func TestLoadBadPEMPrivateKey(t *testing.T) {
 	if _, err := LoadPrivateKeyFromFile(privateKeyPEMFile, []byte{}); err == nil {
 		t.Errorf(`LoadPrivateKeyFromFile(privateKeyPEMFile, []byte{}) = _, %v, want not nil`, err)
 	}
 }
 
+func TestLoadUnencryptedPEMPrivateKey(t *testing.T) {
+	tmpdir := t.TempDir()
+	privateKeyPath := path.Join(tmpdir, "private_key.pem")
+	if err := GeneratED25519Key(nil, privateKeyPath, path.Join(tmpdir, "public_key.pem")); err != nil {
+		t.Fatalf(`GeneratED25519Key(nil, privateKeyPath, path.Join(tmpdir, "public_key.pem")) = %v, want nil`, err)
+	}
+
+	for _, pw := range [][]byte{nil, {}, password} {
+		if _, err := LoadPrivateKeyFromFile(privateKeyPath, pw); err != nil {
+			t.Errorf(`LoadPrivateKeyFromFile(privateKeyPath, %q) = _, %v, want nil`, pw, err)
+		}
+	}
+}
+
+func TestGeneratedKeyEncryption(t *testing.T) {
+	for _, tt := range []struct {
+		password  []byte
+		encrypted bool
+	}{
+		{password: nil, encrypted: false},
+		{password: password, encrypted: true},
+	} {
+		tmpdir := t.TempDir()
+		privateKeyPath := path.Join(tmpdir, "private_key.pem")
+		if err := GeneratED25519Key(tt.password, privateKeyPath, path.Join(tmpdir, "public_key.pem")); err != nil {
+			t.Fatalf(`GeneratED25519Key(%q, privateKeyPath, path.Join(tmpdir, "public_key.pem")) = %v, want nil`, tt.password, err)
+		}
+		x509PEM, err := os.ReadFile(privateKeyPath)
+		if err != nil {
+			t.Fatalf(`os.ReadFile(privateKeyPath) = _, %v, want nil`, err)
+		}
+		block, _ := pem.Decode(x509PEM)
+		if block == nil {
+			t.Fatalf(`pem.Decode(x509PEM) = nil, want PEM block`)
+		}
+		if got := x509.IsEncryptedPEMBlock(block); got != tt.encrypted {
+			t.Errorf(`GeneratED25519Key(%q, ...) wrote encrypted key = %t, want %t`, tt.password, got, tt.encrypted)
+		}
+	}
+}
+
 func TestSignVerifyData(t *testing.T) {
 	privateKey, err := LoadPrivateKeyFromFile(privateKeyPEMFile, password)
 	if err != nil {
@@ -131,3 +280,123 @@ func TestGenerateUnprotectedKeys(t *testing.T) {
 		t.Errorf(`GeneratED25519Key(nil, path.Join(tmpdir, "private_key.pem"), path.Join(tmpdir, "public_key.pem")) = %v, want nil`, err)
 	}
 }
+
+func TestGenerateKeysWithRand(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, ed25519.SeedSize)
+	want := ed25519.NewKeyFromSeed(seed)
+
+	tmpdir := t.TempDir()
+	privateKeyPath := path.Join(tmpdir, "private_key.pem")
+	publicKeyPath := path.Join(tmpdir, "public_key.pem")
+	if err := GenerateED25519KeyWithRand(bytes.NewReader(seed), nil, privateKeyPath, publicKeyPath); err != nil {
+		t.Fatalf(`GenerateED25519KeyWithRand(seed, nil, privateKeyPath, publicKeyPath) = %v, want nil`, err)
+	}
+	privateKey, err := LoadPrivateKeyFromFile(privateKeyPath, nil)
+	if err != nil {
+		t.Fatalf(`LoadPrivateKeyFromFile(privateKeyPath, nil) = _, %v, want nil`, err)
+	}
+	if !bytes.Equal(privateKey, want) {
+		t.Errorf(`LoadPrivateKeyFromFile(privateKeyPath, nil) = %x, want %x`, privateKey, []byte(want))
+	}
+	publicKey, err := LoadPublicKeyFromFile(publicKeyPath)
+	if err != nil {
+		t.Fatalf(`LoadPublicKeyFromFile(publicKeyPath) = _, %v, want nil`, err)
+	}
+	if !bytes.Equal(publicKey, want.Public().(ed25519.PublicKey)) {
+		t.Errorf(`LoadPublicKeyFromFile(publicKeyPath) = %x, want %x`, publicKey, []byte(want.Public().(ed25519.PublicKey)))
+	}
+}
+
+func TestGenerateKeysWithFingerprint(t *testing.T) {
+	tmpdir := t.TempDir()
+	publicKeyPath := path.Join(tmpdir, "public_key.pem")
+	if err := GenerateED25519KeyWithOptions(password, path.Join(tmpdir, "private_key.pem"), publicKeyPath, KeyGenOptions{EmbedFingerprint: true}); err != nil {
+		t.Fatalf(`GenerateED25519KeyWithOptions(password, path.Join(tmpdir, "private_key.pem"), publicKeyPath, {EmbedFingerprint}) = %v, want nil`, err)
+	}
+
+	x509PEM, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		t.Fatalf(`os.ReadFile(publicKeyPath) = _, %v, want nil`, err)
+	}
+	block, _ := pem.Decode(x509PEM)
+	if block == nil {
+		t.Fatalf(`pem.Decode(x509PEM) = nil, want PEM block`)
+	}
+
+	// The loader must skip over the header.
+	publicKey, err := LoadPublicKeyFromFile(publicKeyPath)
+	if err != nil {
+		t.Fatalf(`LoadPublicKeyFromFile(publicKeyPath) = _, %v, want nil`, err)
+	}
+	if got, want := block.Headers[PubKeyFingerprintHeader], Fingerprint(publicKey); got != want {
+		t.Errorf(`block.Headers[PubKeyFingerprintHeader] = %q, want %q`, got, want)
+	}
+}
+
+func TestEd25519Raw(t *testing.T) {
+	privateKey, err := LoadPrivateKeyFromFile(privateKeyPEMFile, password)
+	if err != nil {
+		t.Fatalf(`LoadPrivateKeyFromFile(privateKeyPEMFile, password) = _, %v, want nil`, err)
+	}
+
+	seed := Ed25519ToRaw(privateKey)
+	if len(seed) != ed25519.SeedSize {
+		t.Fatalf(`len(Ed25519ToRaw(privateKey)) = %d, want %d`, len(seed), ed25519.SeedSize)
+	}
+	for _, raw := range [][]byte{seed, privateKey} {
+		got, err := Ed25519FromRaw(raw)
+		if err != nil {
+			t.Fatalf(`Ed25519FromRaw(%d bytes) = _, %v, want nil`, len(raw), err)
+		}
+		if !bytes.Equal(got, privateKey) {
+			t.Errorf(`Ed25519FromRaw(%d bytes) = %x, want %x`, len(raw), got, privateKey)
+		}
+	}
+
+	if _, err := Ed25519FromRaw(seed[1:]); err == nil {
+		t.Errorf(`Ed25519FromRaw(seed[1:]) = _, nil, want error`)
+	}
+	mismatch := append([]byte{}, privateKey...)
+	mismatch[ed25519.PrivateKeySize-1] ^= 0xff
+	if _, err := Ed25519FromRaw(mismatch); err == nil {
+		t.Errorf(`Ed25519FromRaw(mismatch) = _, nil, want error`)
+	}
+}
+
+func TestLoadPublicKeyVerified(t *testing.T) {
+	trustPub, trustPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile, err := os.ReadFile(publicKeyPEMFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpdir := t.TempDir()
+	sigPath := path.Join(tmpdir, "public_key.pem.sig")
+	if err := os.WriteFile(sigPath, ed25519.Sign(trustPriv, keyFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := LoadPublicKeyVerified(publicKeyPEMFile, sigPath, trustPub)
+	if err != nil {
+		t.Fatalf(`LoadPublicKeyVerified(publicKeyPEMFile, sigPath, trustPub) = _, %v, want nil`, err)
+	}
+	if _, ok := pub.(ed25519.PublicKey); !ok {
+		t.Errorf(`LoadPublicKeyVerified() = %T, _, want ed25519.PublicKey`, pub)
+	}
+
+	swapped := path.Join(tmpdir, "swapped.pem")
+	if err := GeneratED25519Key(nil, path.Join(tmpdir, "swapped.key"), swapped); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPublicKeyVerified(swapped, sigPath, trustPub); !errors.Is(err, ErrKeyFileSignature) {
+		t.Errorf(`LoadPublicKeyVerified(swapped, sigPath, trustPub) = _, %v, want %v`, err, ErrKeyFileSignature)
+	}
+	if err := os.WriteFile(sigPath, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPublicKeyVerified(publicKeyPEMFile, sigPath, trustPub); !errors.Is(err, ErrKeyFileSignature) {
+		t.Errorf(`LoadPublicKeyVerified(garbage signature) = _, %v, want %v`, err, ErrKeyFileSignature)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// EncryptionKeySize is the key size of EncryptFile and DecryptFile, which use
+// AES-256-GCM.
+const EncryptionKeySize = 32
+
+// ErrDecryptAuthentication is returned by DecryptFile if the ciphertext was
+// not sealed with the given key or was modified.
+var ErrDecryptAuthentication = errors.New("decryption failed: message authentication failed")
+
+// newGCM returns an AES-256-GCM AEAD with key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("invalid key length %d, want %d", len(key), EncryptionKeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptFile seals the contents of in with AES-256-GCM under key and writes
+// a random nonce followed by the ciphertext to out. It is meant for small
+// secrets, as in is read into memory at once. out is written with
+// PrivKeyFilePermissions.
+func EncryptFile(in, out string, key []byte) error {
+	aead, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	plaintext, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	return os.WriteFile(out, aead.Seal(nonce, nonce, plaintext, nil), PrivKeyFilePermissions)
+}
+
+// DecryptFile opens a file written by EncryptFile with key and writes the
+// plaintext to out with PrivKeyFilePermissions. If the file was not sealed
+// with key or was modified, ErrDecryptAuthentication is returned and out is
+// not written.
+func DecryptFile(in, out string, key []byte) error {
+	aead, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	sealed, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return fmt.Errorf("%w: %d bytes is too short", ErrDecryptAuthentication, len(sealed))
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return ErrDecryptAuthentication
+	}
+	return os.WriteFile(out, plaintext, PrivKeyFilePermissions)
+}
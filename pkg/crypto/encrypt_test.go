@@ -0,0 +1,57 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptDecryptFile(t *testing.T) {
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "secret")
+	sealedPath := filepath.Join(dir, "secret.sealed")
+	openedPath := filepath.Join(dir, "secret.opened")
+	secret := []byte("disk encryption passphrase")
+	if err := os.WriteFile(plainPath, secret, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	key := bytes.Repeat([]byte{0x42}, EncryptionKeySize)
+
+	if err := EncryptFile(plainPath, sealedPath, key); err != nil {
+		t.Fatalf(`EncryptFile() = %v, want nil`, err)
+	}
+	sealed, err := os.ReadFile(sealedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, secret) {
+		t.Errorf(`EncryptFile() wrote the plaintext`)
+	}
+	if err := DecryptFile(sealedPath, openedPath, key); err != nil {
+		t.Fatalf(`DecryptFile() = %v, want nil`, err)
+	}
+	if opened, err := os.ReadFile(openedPath); err != nil || !bytes.Equal(opened, secret) {
+		t.Errorf(`DecryptFile() wrote %q, %v, want %q`, opened, err, secret)
+	}
+
+	otherKey := bytes.Repeat([]byte{0x43}, EncryptionKeySize)
+	if err := DecryptFile(sealedPath, openedPath, otherKey); !errors.Is(err, ErrDecryptAuthentication) {
+		t.Errorf(`DecryptFile(wrong key) = %v, want %v`, err, ErrDecryptAuthentication)
+	}
+	sealed[len(sealed)-1] ^= 1
+	if err := os.WriteFile(sealedPath, sealed, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := DecryptFile(sealedPath, openedPath, key); !errors.Is(err, ErrDecryptAuthentication) {
+		t.Errorf(`DecryptFile(modified) = %v, want %v`, err, ErrDecryptAuthentication)
+	}
+	if err := EncryptFile(plainPath, sealedPath, key[:16]); err == nil {
+		t.Errorf(`EncryptFile(16 byte key) = nil, want error`)
+	}
+}
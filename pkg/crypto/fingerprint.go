@@ -0,0 +1,83 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// ErrUntrustedKey is returned by VerifyWithTrustedFingerprints if the key is
+// not in the trusted set.
+var ErrUntrustedKey = errors.New("public key fingerprint is not trusted")
+
+// Fingerprint returns the SHA-256 fingerprint of the given key bytes as a
+// lower case hex string.
+func Fingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizedKeyID returns an identifier for pub that does not depend on the
+// format the key was stored in: the lower case hex SHA-256 of its PKIX DER
+// encoding. Raw ED25519 keys as returned by the loaders are accepted too.
+func NormalizedKeyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(typedPublicKey(pub))
+	if err != nil {
+		return "", err
+	}
+	return Fingerprint(der), nil
+}
+
+// VerifyWithTrustedFingerprints verifies sig over data with pub like
+// VerifyWithKeyString, but only if pub is pinned in trusted. A key matches
+// if its NormalizedKeyID is in trusted or, for ED25519 keys, the Fingerprint
+// of the raw key as embedded with KeyGenOptions.EmbedFingerprint. ErrUntrustedKey is
+// returned without checking the signature otherwise.
+func VerifyWithTrustedFingerprints(pub crypto.PublicKey, data, sig []byte, trusted []string) (bool, error) {
+	pub = typedPublicKey(pub)
+	ids, err := keyIDs(pub)
+	if err != nil {
+		return false, err
+	}
+	if matchesFingerprint(ids, trusted) {
+		return verifyMessage(pub, data, sig)
+	}
+	return false, ErrUntrustedKey
+}
+
+// keyIDs returns the fingerprints pub is known by: its NormalizedKeyID and,
+// for ED25519 keys, the Fingerprint of the raw key.
+func keyIDs(pub crypto.PublicKey) ([]string, error) {
+	pub = typedPublicKey(pub)
+	id, err := NormalizedKeyID(pub)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{id}
+	if key, ok := pub.(ed25519.PublicKey); ok {
+		ids = append(ids, Fingerprint(key))
+	}
+	return ids, nil
+}
+
+// matchesFingerprint reports whether any of ids is in fingerprints, ignoring
+// case and surrounding white space.
+func matchesFingerprint(ids, fingerprints []string) bool {
+	for _, fp := range fingerprints {
+		for _, id := range ids {
+			if strings.EqualFold(strings.TrimSpace(fp), id) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestFingerprint(t *testing.T) {
+	// echo -n abc | sha256sum
+	want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if got := Fingerprint([]byte("abc")); got != want {
+		t.Errorf(`Fingerprint("abc") = %q, want %q`, got, want)
+	}
+}
+
+func TestNormalizedKeyID(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	fromDER, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(pemBytes)
+	fromPEM, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	derID, err := NormalizedKeyID(fromDER)
+	if err != nil {
+		t.Fatalf(`NormalizedKeyID(fromDER) = _, %v, want nil`, err)
+	}
+	pemID, err := NormalizedKeyID(fromPEM)
+	if err != nil {
+		t.Fatalf(`NormalizedKeyID(fromPEM) = _, %v, want nil`, err)
+	}
+	if derID != pemID {
+		t.Errorf(`NormalizedKeyID(fromDER) = %q, NormalizedKeyID(fromPEM) = %q, want equal`, derID, pemID)
+	}
+	if derID != Fingerprint(der) {
+		t.Errorf(`NormalizedKeyID(fromDER) = %q, want %q`, derID, Fingerprint(der))
+	}
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typedID, err := NormalizedKeyID(pub)
+	if err != nil {
+		t.Fatalf(`NormalizedKeyID(pub) = _, %v, want nil`, err)
+	}
+	rawID, err := NormalizedKeyID([]byte(pub))
+	if err != nil {
+		t.Fatalf(`NormalizedKeyID([]byte(pub)) = _, %v, want nil`, err)
+	}
+	if typedID != rawID {
+		t.Errorf(`NormalizedKeyID([]byte(pub)) = %q, want %q`, rawID, typedID)
+	}
+
+	if _, err := NormalizedKeyID("not a key"); err == nil {
+		t.Errorf(`NormalizedKeyID("not a key") = _, nil, want error`)
+	}
+}
+
+func TestVerifyWithTrustedFingerprints(t *testing.T) {
+	data := []byte("boot artifact")
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := ed25519.Sign(priv, data)
+	id, err := NormalizedKeyID(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherID, err := NormalizedKeyID(otherPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, trusted := range [][]string{
+		{otherID, id},
+		{strings.ToUpper(id)},
+		{Fingerprint(pub)},
+	} {
+		if ok, err := VerifyWithTrustedFingerprints([]byte(pub), data, sig, trusted); err != nil || !ok {
+			t.Errorf(`VerifyWithTrustedFingerprints(pub, data, sig, %q) = %t, %v, want true, nil`, trusted, ok, err)
+		}
+	}
+	if ok, err := VerifyWithTrustedFingerprints(pub, data[1:], sig, []string{id}); err != nil || ok {
+		t.Errorf(`VerifyWithTrustedFingerprints(pub, data[1:], sig, trusted) = %t, %v, want false, nil`, ok, err)
+	}
+	// An untrusted key is rejected even with a malformed signature.
+	if _, err := VerifyWithTrustedFingerprints(pub, data, nil, []string{otherID}); !errors.Is(err, ErrUntrustedKey) {
+		t.Errorf(`VerifyWithTrustedFingerprints(untrusted) = _, %v, want %v`, err, ErrUntrustedKey)
+	}
+}
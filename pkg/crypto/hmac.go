@@ -0,0 +1,27 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/hmac"
+)
+
+// SignHMAC returns the HMAC of data under key using h. Like crypto.Hash.New,
+// it panics if h is not linked into the binary.
+func SignHMAC(key, data []byte, h crypto.Hash) []byte {
+	mac := hmac.New(h.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// VerifyHMAC reports whether mac is the HMAC of data under key using h. The
+// comparison is done in constant time.
+func VerifyHMAC(key, data, mac []byte, h crypto.Hash) bool {
+	if !h.Available() {
+		return false
+	}
+	return hmac.Equal(SignHMAC(key, data, h), mac)
+}
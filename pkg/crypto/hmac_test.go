@@ -0,0 +1,40 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSignHMAC(t *testing.T) {
+	// RFC 4231 test case 2
+	key := []byte("Jefe")
+	data := []byte("what do ya want for nothing?")
+	want := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got := hex.EncodeToString(SignHMAC(key, data, crypto.SHA256)); got != want {
+		t.Errorf(`SignHMAC(key, data, crypto.SHA256) = %s, want %s`, got, want)
+	}
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	key := []byte("provisioned key")
+	data := []byte("boot artifact")
+	mac := SignHMAC(key, data, crypto.SHA512)
+
+	if !VerifyHMAC(key, data, mac, crypto.SHA512) {
+		t.Errorf(`VerifyHMAC(key, data, mac, crypto.SHA512) = false, want true`)
+	}
+	if VerifyHMAC([]byte("other key"), data, mac, crypto.SHA512) {
+		t.Errorf(`VerifyHMAC(otherKey, data, mac, crypto.SHA512) = true, want false`)
+	}
+	if VerifyHMAC(key, data, mac[1:], crypto.SHA512) {
+		t.Errorf(`VerifyHMAC(key, data, mac[1:], crypto.SHA512) = true, want false`)
+	}
+	if VerifyHMAC(key, data, mac, crypto.SHA256) {
+		t.Errorf(`VerifyHMAC(key, data, mac, crypto.SHA256) = true, want false`)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// JWKThumbprint returns the RFC 7638 thumbprint of pub: the unpadded base64url
+// SHA-256 of the required members of its JWK in lexicographic order. It can
+// be used as JWS "kid" and, unlike NormalizedKeyID, can be computed by any
+// JOSE library. ED25519 keys are thumbprinted as OKP keys as in RFC 8037,
+// raw ED25519 keys as returned by the loaders are accepted too.
+func JWKThumbprint(pub crypto.PublicKey) (string, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	var jwk string
+	switch key := typedPublicKey(pub).(type) {
+	case ed25519.PublicKey:
+		jwk = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, b64(key))
+	case *rsa.PublicKey:
+		jwk = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, b64(big.NewInt(int64(key.E)).Bytes()), b64(key.N.Bytes()))
+	case *ecdsa.PublicKey:
+		params := key.Curve.Params()
+		size := (params.BitSize + 7) / 8
+		// The coordinates have the full size of the field.
+		x := key.X.FillBytes(make([]byte, size))
+		y := key.Y.FillBytes(make([]byte, size))
+		jwk = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, params.Name, b64(x), b64(y))
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", pub)
+	}
+	sum := sha256.Sum256([]byte(jwk))
+	return b64(sum[:]), nil
+}
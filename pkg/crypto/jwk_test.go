@@ -0,0 +1,56 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestJWKThumbprint(t *testing.T) {
+	mustDecode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	// RFC 7638, section 3.1.
+	rsaKey := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(mustDecode("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")),
+		E: 65537,
+	}
+	// RFC 8037, appendix A.3.
+	edKey := ed25519.PublicKey(mustDecode("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"))
+
+	for _, tt := range []struct {
+		name string
+		pub  any
+		want string
+	}{
+		{"RSA", rsaKey, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"},
+		{"ED25519", edKey, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"},
+		{"raw ED25519", []byte(edKey), "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"},
+	} {
+		got, err := JWKThumbprint(tt.pub)
+		if err != nil || got != tt.want {
+			t.Errorf(`JWKThumbprint(%s) = %q, %v, want %q, nil`, tt.name, got, err, tt.want)
+		}
+	}
+
+	x, y := elliptic.P256().ScalarBaseMult(big.NewInt(1).Bytes())
+	if got, err := JWKThumbprint(&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}); err != nil || len(got) != 43 {
+		t.Errorf(`JWKThumbprint(ECDSA) = %q, %v, want 43 character thumbprint`, got, err)
+	}
+	if _, err := JWKThumbprint("not a key"); err == nil {
+		t.Errorf(`JWKThumbprint("not a key") = _, nil, want error`)
+	}
+}
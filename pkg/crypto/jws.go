@@ -0,0 +1,117 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// ErrJWSInvalidSignature is returned by VerifyJWS if the signature does not verify.
+var ErrJWSInvalidSignature = errors.New("invalid JWS signature")
+
+type jwsHeader struct {
+	Alg string `json:"alg"`
+}
+
+// jwsHashes maps the supported RSA and ECDSA JWS algorithms to their hash.
+var jwsHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// jwsCurves maps the ECDSA JWS algorithms to the curve they require.
+var jwsCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// VerifyJWS verifies a JWS in compact serialization with pub and returns its
+// decoded payload. The algorithm is taken from the protected header and has
+// to match the type of pub; EdDSA, RS256/384/512 and ES256/384/512 are
+// supported.
+func VerifyJWS(compact string, pub crypto.PublicKey) ([]byte, error) {
+	parts := strings.Split(compact, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("JWS has %d parts, want 3", len(parts))
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("can't decode JWS header: %w", err)
+	}
+	var header jwsHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, fmt.Errorf("can't parse JWS header: %w", err)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("can't decode JWS payload: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("can't decode JWS signature: %w", err)
+	}
+
+	signingInput := parts[0] + "." + parts[1]
+	pub = typedPublicKey(pub)
+	var ok bool
+	switch key := pub.(type) {
+	case ed25519.PublicKey:
+		if header.Alg != "EdDSA" {
+			return nil, fmt.Errorf("JWS algorithm %q does not match ED25519 key", header.Alg)
+		}
+		if err := checkSignatureLength(key, sig); err != nil {
+			return nil, err
+		}
+		ok = ed25519.Verify(key, []byte(signingInput), sig)
+	case *rsa.PublicKey:
+		h, found := jwsHashes[header.Alg]
+		if !found || !strings.HasPrefix(header.Alg, "RS") {
+			return nil, fmt.Errorf("JWS algorithm %q does not match RSA key", header.Alg)
+		}
+		ok, err = VerifyReader(key, strings.NewReader(signingInput), sig, h)
+	case *ecdsa.PublicKey:
+		curve, found := jwsCurves[header.Alg]
+		if !found || curve != key.Curve {
+			return nil, fmt.Errorf("JWS algorithm %q does not match ECDSA key", header.Alg)
+		}
+		// JWS carries the raw concatenation of r and s.
+		size := (curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return nil, ErrJWSInvalidSignature
+		}
+		digest, err := hashReader(strings.NewReader(signingInput), jwsHashes[header.Alg])
+		if err != nil {
+			return nil, err
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		ok = ecdsa.Verify(key, digest, r, s)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrJWSInvalidSignature
+	}
+	return payload, nil
+}
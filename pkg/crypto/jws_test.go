@@ -0,0 +1,106 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// signJWS creates a compact JWS of payload, sign returns the raw JWS
+// signature of the signing input.
+func signJWS(t *testing.T, alg string, payload string, sign func(signingInput []byte) ([]byte, error)) string {
+	t.Helper()
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"`+alg+`"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	sig, err := sign([]byte(signingInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerifyJWS(t *testing.T) {
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const payload = `{"policy":"boot"}`
+
+	edJWS := signJWS(t, "EdDSA", payload, func(in []byte) ([]byte, error) {
+		return ed25519.Sign(edPriv, in), nil
+	})
+	rsaJWS := signJWS(t, "RS256", payload, func(in []byte) ([]byte, error) {
+		digest := sha256.Sum256(in)
+		return rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	})
+	ecJWS := signJWS(t, "ES256", payload, func(in []byte) ([]byte, error) {
+		digest := sha256.Sum256(in)
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	})
+
+	for _, tt := range []struct {
+		name    string
+		compact string
+		pub     crypto.PublicKey
+		wantErr error
+	}{
+		{name: "EdDSA", compact: edJWS, pub: edPub},
+		{name: "EdDSA raw key", compact: edJWS, pub: []byte(edPub)},
+		{name: "RS256", compact: rsaJWS, pub: &rsaKey.PublicKey},
+		{name: "ES256", compact: ecJWS, pub: &ecKey.PublicKey},
+		{name: "tampered", compact: edJWS[:len(edJWS)-4] + "AAAA", pub: edPub, wantErr: ErrJWSInvalidSignature},
+		{name: "truncated", compact: edJWS[:len(edJWS)-8], pub: edPub, wantErr: ErrMalformedSignature},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyJWS(tt.compact, tt.pub)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf(`VerifyJWS() = _, %v, want %v`, err, tt.wantErr)
+			}
+			if err == nil && string(got) != payload {
+				t.Errorf(`VerifyJWS() = %q, want %q`, got, payload)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		name    string
+		compact string
+		pub     crypto.PublicKey
+	}{
+		{name: "algorithm mismatch", compact: rsaJWS, pub: &ecKey.PublicKey},
+		{name: "two parts", compact: "a.b", pub: edPub},
+		{name: "bad header", compact: "!!.b.c", pub: edPub},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := VerifyJWS(tt.compact, tt.pub); err == nil {
+				t.Errorf(`VerifyJWS() = _, nil, want error`)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// KeyInfoSuffix is appended to the public key path to name its KeyInfo sidecar.
+const KeyInfoSuffix = ".info"
+
+// KeyInfo is the provenance of a generated key pair. It is stored as JSON in
+// a sidecar file next to the public key, see KeyGenOptions.WriteKeyInfo, so
+// that the PEM files stay untouched.
+type KeyInfo struct {
+	Algorithm   string    `json:"algorithm"`
+	Created     time.Time `json:"created"`
+	Fingerprint string    `json:"fingerprint"`
+}
+
+func writeKeyInfo(publicKeyPath string, info KeyInfo) error {
+	b, err := json.MarshalIndent(info, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(publicKeyPath+KeyInfoSuffix, append(b, '\n'), PubKeyFilePermissions)
+}
+
+// LoadKeyInfo reads the KeyInfo sidecar of the public key at publicKeyPath.
+func LoadKeyInfo(publicKeyPath string) (KeyInfo, error) {
+	var info KeyInfo
+	b, err := os.ReadFile(publicKeyPath + KeyInfoSuffix)
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(b, &info)
+	return info, err
+}
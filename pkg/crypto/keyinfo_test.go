@@ -0,0 +1,44 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"path"
+	"testing"
+	"time"
+)
+
+func TestGenerateKeysWithKeyInfo(t *testing.T) {
+	tmpdir := t.TempDir()
+	publicKeyPath := path.Join(tmpdir, "public_key.pem")
+	before := time.Now()
+	if err := GenerateED25519KeyWithOptions(nil, path.Join(tmpdir, "private_key.pem"), publicKeyPath, KeyGenOptions{WriteKeyInfo: true}); err != nil {
+		t.Fatalf(`GenerateED25519KeyWithOptions(nil, path.Join(tmpdir, "private_key.pem"), publicKeyPath, {WriteKeyInfo}) = %v, want nil`, err)
+	}
+
+	info, err := LoadKeyInfo(publicKeyPath)
+	if err != nil {
+		t.Fatalf(`LoadKeyInfo(publicKeyPath) = _, %v, want nil`, err)
+	}
+	publicKey, err := LoadPublicKeyFromFile(publicKeyPath)
+	if err != nil {
+		t.Fatalf(`LoadPublicKeyFromFile(publicKeyPath) = _, %v, want nil`, err)
+	}
+	if info.Algorithm != "ed25519" {
+		t.Errorf(`LoadKeyInfo(publicKeyPath).Algorithm = %q, want "ed25519"`, info.Algorithm)
+	}
+	if want := Fingerprint(publicKey); info.Fingerprint != want {
+		t.Errorf(`LoadKeyInfo(publicKeyPath).Fingerprint = %q, want %q`, info.Fingerprint, want)
+	}
+	if info.Created.Before(before.Add(-time.Second)) || info.Created.After(time.Now()) {
+		t.Errorf(`LoadKeyInfo(publicKeyPath).Created = %v, want around %v`, info.Created, before)
+	}
+}
+
+func TestLoadKeyInfoMissing(t *testing.T) {
+	if _, err := LoadKeyInfo(publicKeyPEMFile); err == nil {
+		t.Errorf(`LoadKeyInfo(publicKeyPEMFile) = _, nil, want error`)
+	}
+}
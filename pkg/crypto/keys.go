@@ -0,0 +1,128 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"math/big"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// typedPrivateKey turns the raw ED25519 keys returned by the loaders into
+// ed25519.PrivateKey and returns all other keys unchanged.
+func typedPrivateKey(priv crypto.PrivateKey) crypto.PrivateKey {
+	if b, ok := priv.([]byte); ok && len(b) == ed25519.PrivateKeySize {
+		return ed25519.PrivateKey(b)
+	}
+	return priv
+}
+
+// typedPublicKey turns the raw ED25519 keys returned by the loaders into
+// ed25519.PublicKey and returns all other keys unchanged.
+func typedPublicKey(pub crypto.PublicKey) crypto.PublicKey {
+	if b, ok := pub.([]byte); ok && len(b) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(b)
+	}
+	return pub
+}
+
+// KeyPairMatches reports whether pub is the public half of priv. Both may be
+// raw ED25519 keys as returned by the loaders, or any of the standard library
+// key types.
+func KeyPairMatches(priv crypto.PrivateKey, pub crypto.PublicKey) bool {
+	signer, ok := typedPrivateKey(priv).(interface{ Public() crypto.PublicKey })
+	if !ok {
+		return false
+	}
+	derived, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok {
+		return false
+	}
+	return derived.Equal(typedPublicKey(pub))
+}
+
+// Wipe overwrites the key material of priv with zeros, e.g. once it is no
+// longer needed for signing. The raw ED25519 keys returned by the loaders can
+// be passed as well.
+//
+// This is best effort only: the Go runtime may have copied the key while
+// moving or growing memory, and any copies made by the caller or derived
+// keys, like the ed25519.PrivateKey returned by Ed25519FromRaw, are untouched.
+func Wipe(priv ed25519.PrivateKey) {
+	WipeBytes(priv)
+}
+
+// WipeBytes overwrites b with zeros, e.g. a passphrase or the raw key bytes
+// returned by LoadPrivateKey. The caveats of Wipe apply.
+func WipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// wipeBigInt overwrites the words of n with zeros and sets it to 0.
+func wipeBigInt(n *big.Int) {
+	if n == nil {
+		return
+	}
+	words := n.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	n.SetInt64(0)
+}
+
+// WipeKey is like Wipe for all private keys of this package: ED25519 keys,
+// raw keys as returned by the loaders, and the secret values of
+// *ecdsa.PrivateKey and *rsa.PrivateKey, whose public halves are kept. Other
+// keys, e.g. held by hardware signers, are left alone. Besides the caveats
+// of Wipe, the standard library may keep its own precomputed copies of RSA
+// keys.
+func WipeKey(priv crypto.PrivateKey) {
+	switch key := priv.(type) {
+	case ed25519.PrivateKey:
+		Wipe(key)
+	case []byte:
+		WipeBytes(key)
+	case *ecdsa.PrivateKey:
+		wipeBigInt(key.D)
+	case *rsa.PrivateKey:
+		wipeBigInt(key.D)
+		for _, prime := range key.Primes {
+			wipeBigInt(prime)
+		}
+		wipeBigInt(key.Precomputed.Dp)
+		wipeBigInt(key.Precomputed.Dq)
+		wipeBigInt(key.Precomputed.Qinv)
+	}
+}
+
+// LoadKeyPair loads the private key at privPath, decrypting it with password
+// if needed, and derives its public key, so signers do not need a separate
+// public key file. ED25519 keys are returned as ed25519.PrivateKey and
+// ed25519.PublicKey, other keys as parsed by crypto/x509.
+func LoadKeyPair(privPath string, password []byte) (priv crypto.PrivateKey, pub crypto.PublicKey, err error) {
+	raw, err := LoadPrivateKeyFromFile(privPath, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(raw) == ed25519.PrivateKeySize {
+		priv = ed25519.PrivateKey(raw)
+	} else if priv, err = x509.ParsePKCS8PrivateKey(raw); err != nil {
+		if priv, err = x509.ParseECPrivateKey(raw); err != nil {
+			return nil, nil, errors.New("unsupported private key format")
+		}
+	}
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, nil, errors.New("private key does not provide a public key")
+	}
+	return priv, signer.Public(), nil
+}
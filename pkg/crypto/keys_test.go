@@ -0,0 +1,124 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestKeyPairMatches(t *testing.T) {
+	privateKey, err := LoadPrivateKeyFromFile(privateKeyPEMFile, password)
+	if err != nil {
+		t.Fatalf(`LoadPrivateKeyFromFile(privateKeyPEMFile, password) = _, %v, want nil`, err)
+	}
+	publicKey, err := LoadPublicKeyFromFile(publicKeyPEMFile)
+	if err != nil {
+		t.Fatalf(`LoadPublicKeyFromFile(publicKeyPEMFile) = _, %v, want nil`, err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherECDSAKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		priv crypto.PrivateKey
+		pub  crypto.PublicKey
+		want bool
+	}{
+		{name: "ed25519", priv: privateKey, pub: publicKey, want: true},
+		{name: "ecdsa", priv: ecdsaKey, pub: &ecdsaKey.PublicKey, want: true},
+		{name: "ecdsa mismatch", priv: ecdsaKey, pub: &otherECDSAKey.PublicKey, want: false},
+		{name: "mixed types", priv: privateKey, pub: &ecdsaKey.PublicKey, want: false},
+		{name: "not a key", priv: "key", pub: publicKey, want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeyPairMatches(tt.priv, tt.pub); got != tt.want {
+				t.Errorf(`KeyPairMatches() = %t, want %t`, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWipe(t *testing.T) {
+	privateKey, err := LoadPrivateKeyFromFile(privateKeyPEMFile, password)
+	if err != nil {
+		t.Fatalf(`LoadPrivateKeyFromFile(privateKeyPEMFile, password) = _, %v, want nil`, err)
+	}
+	Wipe(privateKey)
+	if !bytes.Equal(privateKey, make([]byte, len(privateKey))) {
+		t.Errorf(`Wipe(privateKey) left %x, want zeros`, privateKey)
+	}
+}
+
+func TestWipeKey(t *testing.T) {
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := append([]byte(nil), edPriv...)
+	passphrase := []byte("keins")
+
+	WipeKey(edPriv)
+	WipeKey(raw)
+	WipeKey(ecKey)
+	WipeKey(rsaKey)
+	WipeBytes(passphrase)
+	for name, b := range map[string][]byte{"ed25519": edPriv, "raw": raw, "passphrase": passphrase} {
+		if !bytes.Equal(b, make([]byte, len(b))) {
+			t.Errorf(`WipeKey(%s) left %x, want zeros`, name, b)
+		}
+	}
+	if ecKey.D.Sign() != 0 || ecKey.X.Sign() == 0 {
+		t.Errorf(`WipeKey(ECDSA key) = D %v, X %v, want D zeroed and X kept`, ecKey.D, ecKey.X)
+	}
+	for name, n := range map[string]*big.Int{"D": rsaKey.D, "P": rsaKey.Primes[0], "Q": rsaKey.Primes[1], "Dp": rsaKey.Precomputed.Dp, "Dq": rsaKey.Precomputed.Dq, "Qinv": rsaKey.Precomputed.Qinv} {
+		if n.Sign() != 0 {
+			t.Errorf(`WipeKey(RSA key) left %s = %v, want 0`, name, n)
+		}
+	}
+	if rsaKey.N.Sign() == 0 {
+		t.Errorf(`WipeKey(RSA key) zeroed N, want it kept`)
+	}
+}
+
+func TestLoadKeyPair(t *testing.T) {
+	priv, pub, err := LoadKeyPair(privateKeyPEMFile, password)
+	if err != nil {
+		t.Fatalf(`LoadKeyPair(privateKeyPEMFile, password) = _, _, %v, want nil`, err)
+	}
+	publicKey, err := LoadPublicKeyFromFile(publicKeyPEMFile)
+	if err != nil {
+		t.Fatalf(`LoadPublicKeyFromFile(publicKeyPEMFile) = _, %v, want nil`, err)
+	}
+	if !KeyPairMatches(priv, publicKey) || !KeyPairMatches(priv, pub) {
+		t.Errorf(`LoadKeyPair(privateKeyPEMFile, password) returned a key pair not matching publicKeyPEMFile`)
+	}
+
+	if _, _, err := LoadKeyPair(privateKeyPEMFile, []byte("wrong password")); err == nil {
+		t.Errorf(`LoadKeyPair(privateKeyPEMFile, "wrong password") = _, _, nil, want error`)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestSignaturePrefix starts the last line of a manifest, which holds the
+// base64 encoded signature over all preceding lines.
+const ManifestSignaturePrefix = "signature: "
+
+// ErrManifestSignature is returned by VerifyManifest if the manifest's
+// signature does not verify.
+var ErrManifestSignature = errors.New("manifest signature verification failed")
+
+// VerifyManifest verifies the signed manifest at manifestPath with pub and
+// checks the files it lists below root.
+//
+// A manifest consists of one "<path> <sha256 hex>" line per file, followed by
+// a single line starting with ManifestSignaturePrefix. The signature covers
+// all bytes before that line; it is an Ed25519 signature of the bytes or an
+// RSA PKCS#1 v1.5 or ECDSA signature of their SHA-256 digest, depending on
+// pub. Paths are relative to root and may not leave it.
+func VerifyManifest(manifestPath string, pub crypto.PublicKey, root string) error {
+	manifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	i := bytes.LastIndex(manifest, []byte("\n"+ManifestSignaturePrefix))
+	if i < 0 {
+		return errors.New("manifest has no signature")
+	}
+	body := manifest[:i+1]
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(manifest[i+1+len(ManifestSignaturePrefix):])))
+	if err != nil {
+		return fmt.Errorf("can't decode manifest signature: %w", err)
+	}
+	ok, err := verifyMessage(typedPublicKey(pub), body, sig)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrManifestSignature
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			return fmt.Errorf("manifest line %d: want \"<path> <sha256>\", got %q", line, scanner.Text())
+		}
+		if err := verifyManifestEntry(root, fields[0], fields[1]); err != nil {
+			return fmt.Errorf("manifest line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func verifyManifestEntry(root, path, sum string) error {
+	want, err := hex.DecodeString(sum)
+	if err != nil || len(want) != sha256.Size {
+		return fmt.Errorf("invalid sha256 %q for %q", sum, path)
+	}
+	rel := filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("path %q is not below the root", path)
+	}
+
+	f, err := os.Open(filepath.Join(root, rel))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), want) {
+		return fmt.Errorf("sha256 of %q is %x, want %s", path, h.Sum(nil), sum)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// writeManifest writes a manifest of the given lines signed with sign.
+func writeManifest(t *testing.T, path string, lines []string, sign func(body []byte) []byte) {
+	t.Helper()
+	var body bytes.Buffer
+	for _, line := range lines {
+		body.WriteString(line + "\n")
+	}
+	sig := base64.StdEncoding.EncodeToString(sign(body.Bytes()))
+	if err := os.WriteFile(path, append(body.Bytes(), ManifestSignaturePrefix+sig+"\n"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyManifest(t *testing.T) {
+	root := t.TempDir()
+	kernel := []byte("kernel image")
+	if err := os.MkdirAll(filepath.Join(root, "boot"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "boot", "kernel"), kernel, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	kernelLine := fmt.Sprintf("boot/kernel %x", sha256.Sum256(kernel))
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signEd25519 := func(body []byte) []byte {
+		return ed25519.Sign(priv, body)
+	}
+	signECDSA := func(body []byte) []byte {
+		sig, err := SignReader(ecdsaKey, bytes.NewReader(body), crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		lines   []string
+		sign    func([]byte) []byte
+		pub     crypto.PublicKey
+		wantErr bool
+		errIs   error
+	}{
+		{name: "ed25519", lines: []string{kernelLine}, sign: signEd25519, pub: pub},
+		{name: "raw ed25519 key", lines: []string{kernelLine}, sign: signEd25519, pub: []byte(pub)},
+		{name: "ecdsa", lines: []string{kernelLine}, sign: signECDSA, pub: &ecdsaKey.PublicKey},
+		{name: "wrong key", lines: []string{kernelLine}, sign: signEd25519, pub: otherPub, wantErr: true, errIs: ErrManifestSignature},
+		{name: "hash mismatch", lines: []string{fmt.Sprintf("boot/kernel %x", sha256.Sum256([]byte("other")))}, sign: signEd25519, pub: pub, wantErr: true},
+		{name: "missing file", lines: []string{fmt.Sprintf("boot/initramfs %x", sha256.Sum256(kernel))}, sign: signEd25519, pub: pub, wantErr: true},
+		{name: "escapes root", lines: []string{fmt.Sprintf("../kernel %x", sha256.Sum256(kernel))}, sign: signEd25519, pub: pub, wantErr: true},
+		{name: "malformed line", lines: []string{"boot/kernel"}, sign: signEd25519, pub: pub, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), "manifest")
+			writeManifest(t, manifestPath, tt.lines, tt.sign)
+			err := VerifyManifest(manifestPath, tt.pub, root)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf(`VerifyManifest() = %v, want error %t`, err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf(`VerifyManifest() = %v, want %v`, err, tt.errIs)
+			}
+		})
+	}
+
+	unsigned := filepath.Join(t.TempDir(), "manifest")
+	if err := os.WriteFile(unsigned, []byte(kernelLine+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyManifest(unsigned, pub, root); err == nil {
+		t.Errorf(`VerifyManifest(unsigned) = nil, want error`)
+	}
+}
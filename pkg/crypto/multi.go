@@ -0,0 +1,62 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// multiDigest returns the digest SignMulti signs: the hash with h of the
+// number of inputs followed by every input prefixed with its length, all
+// lengths as big-endian uint64, so that moving bytes between inputs or
+// splitting them differently changes the digest.
+func multiDigest(h crypto.Hash, inputs [][]byte) ([]byte, error) {
+	if !h.Available() {
+		return nil, fmt.Errorf("hash function %v is not available", h)
+	}
+	hasher := h.New()
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(inputs)))
+	hasher.Write(length[:])
+	for _, input := range inputs {
+		binary.BigEndian.PutUint64(length[:], uint64(len(input)))
+		hasher.Write(length[:])
+		hasher.Write(input)
+	}
+	return hasher.Sum(nil), nil
+}
+
+// SignMulti signs inputs as a unit, e.g. a kernel, its initramfs and its
+// command line, hashing them with h in a canonical, length prefixed framing.
+// The order of inputs matters. ED25519 signers sign the combined digest as
+// message, all others sign it as digest made with h.
+func SignMulti(signer crypto.Signer, h crypto.Hash, inputs ...[]byte) ([]byte, error) {
+	digest, err := multiDigest(h, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := typedPublicKey(signer.Public()).(ed25519.PublicKey); ok {
+		return signer.Sign(nil, digest, crypto.Hash(0))
+	}
+	return signer.Sign(nil, digest, h)
+}
+
+// VerifyMulti verifies sig made by SignMulti over inputs, in the same order,
+// with pub.
+func VerifyMulti(pub crypto.PublicKey, sig []byte, h crypto.Hash, inputs ...[]byte) (bool, error) {
+	digest, err := multiDigest(h, inputs)
+	if err != nil {
+		return false, err
+	}
+	pub = typedPublicKey(pub)
+	if _, ok := pub.(ed25519.PublicKey); ok {
+		return verifyMessage(pub, digest, sig)
+	}
+	return verifyDigest(pub, digest, sig, h, RSAVerifyOptions{})
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestSignVerifyMulti(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kernel, initramfs := []byte("bzImage"), []byte("initramfs")
+
+	for _, tt := range []struct {
+		name   string
+		signer crypto.Signer
+	}{
+		{"ed25519", edKey},
+		{"rsa", rsaKey},
+		{"ecdsa", ecKey},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := SignMulti(tt.signer, crypto.SHA256, kernel, initramfs)
+			if err != nil {
+				t.Fatalf(`SignMulti() = _, %v, want nil`, err)
+			}
+			pub := tt.signer.Public()
+			if ok, err := VerifyMulti(pub, sig, crypto.SHA256, kernel, initramfs); !ok || err != nil {
+				t.Errorf(`VerifyMulti() = %t, %v, want true, nil`, ok, err)
+			}
+			for _, inputs := range [][][]byte{
+				{initramfs, kernel},
+				{[]byte("bzImageinitramfs")},
+				{[]byte("bzImagei"), []byte("nitramfs")},
+				{kernel, initramfs, nil},
+			} {
+				if ok, _ := VerifyMulti(pub, sig, crypto.SHA256, inputs...); ok {
+					t.Errorf(`VerifyMulti(%q) = true, want false`, inputs)
+				}
+			}
+		})
+	}
+
+	if _, err := SignMulti(ecKey, crypto.Hash(0), kernel); err == nil {
+		t.Errorf(`SignMulti(unavailable hash) = _, nil, want error`)
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"encoding/pem"
+	"os"
+)
+
+// PEMBlockTypes returns the types of all PEM blocks in the file at path in
+// the order they appear, e.g. to tell users which keys or certificates a file
+// contains when it does not load. Data between blocks is ignored.
+func PEMBlockTypes(path string) ([]string, error) {
+	rest, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var types []string
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return types, nil
+		}
+		types = append(types, block.Type)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"encoding/pem"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestPEMBlockTypes(t *testing.T) {
+	bundle := path.Join(t.TempDir(), "bundle.pem")
+	var b []byte
+	for _, typ := range []string{CertIdentifier, PrivKeyIdentifier} {
+		b = append(b, "comment\n"...)
+		b = append(b, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: []byte("x")})...)
+	}
+	if err := os.WriteFile(bundle, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		path string
+		want []string
+	}{
+		{path: bundle, want: []string{CertIdentifier, PrivKeyIdentifier}},
+		{path: publicKeyPEMFile, want: []string{PubKeyIdentifier}},
+		{path: publicKeyDERFile, want: nil},
+	} {
+		got, err := PEMBlockTypes(tt.path)
+		if err != nil {
+			t.Fatalf(`PEMBlockTypes(%q) = _, %v, want nil`, tt.path, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf(`PEMBlockTypes(%q) = %q, want %q`, tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := PEMBlockTypes(path.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf(`PEMBlockTypes(missing) = _, nil, want error`)
+	}
+}
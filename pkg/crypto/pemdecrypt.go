@@ -0,0 +1,100 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// rfc1423Cipher is a cipher of legacy RFC 1423 PEM encryption.
+type rfc1423Cipher struct {
+	keySize   int
+	newCipher func(key []byte) (cipher.Block, error)
+}
+
+// rfc1423Ciphers are the DEK-Info ciphers supported by x509.EncryptPEMBlock.
+var rfc1423Ciphers = map[string]rfc1423Cipher{
+	"DES-CBC":      {keySize: 8, newCipher: des.NewCipher},
+	"DES-EDE3-CBC": {keySize: 24, newCipher: des.NewTripleDESCipher},
+	"AES-128-CBC":  {keySize: 16, newCipher: aes.NewCipher},
+	"AES-192-CBC":  {keySize: 24, newCipher: aes.NewCipher},
+	"AES-256-CBC":  {keySize: 32, newCipher: aes.NewCipher},
+}
+
+// decryptPEMBlock decrypts a PEM block encrypted with x509.EncryptPEMBlock.
+// Unlike x509.DecryptPEMBlock, the padding is checked in constant time, so
+// that how far a wrong passphrase gets does not show in the timing: the key
+// is always fully derived, the whole block decrypted and every padding byte
+// compared, and all failures return ErrWrongPassphrase.
+func decryptPEMBlock(block *pem.Block, password []byte) ([]byte, error) {
+	dek, ok := block.Headers["DEK-Info"]
+	if !ok {
+		return nil, errors.New("no DEK-Info header in encrypted PEM block")
+	}
+	mode, hexIV, ok := strings.Cut(dek, ",")
+	if !ok {
+		return nil, fmt.Errorf("malformed DEK-Info header %q", dek)
+	}
+	c, ok := rfc1423Ciphers[mode]
+	if !ok {
+		return nil, fmt.Errorf("unsupported PEM encryption %q", mode)
+	}
+	iv, err := hex.DecodeString(hexIV)
+	if err != nil {
+		return nil, fmt.Errorf("malformed DEK-Info IV: %w", err)
+	}
+
+	// The key derivation of RFC 1423 as implemented by OpenSSL's
+	// EVP_BytesToKey with MD5, a single iteration and the first 8 bytes of
+	// the IV as salt.
+	if len(iv) < 8 {
+		return nil, errors.New("DEK-Info IV is too short")
+	}
+	var key []byte
+	for digest := []byte(nil); len(key) < c.keySize; {
+		h := md5.New()
+		h.Write(digest)
+		h.Write(password)
+		h.Write(iv[:8])
+		digest = h.Sum(nil)
+		key = append(key, digest...)
+	}
+	b, err := c.newCipher(key[:c.keySize])
+	if err != nil {
+		return nil, err
+	}
+	blockSize := b.BlockSize()
+	if len(iv) != blockSize {
+		return nil, errors.New("DEK-Info IV does not match the cipher block size")
+	}
+	if len(block.Bytes) == 0 || len(block.Bytes)%blockSize != 0 {
+		return nil, errors.New("encrypted PEM data is not a multiple of the block size")
+	}
+
+	data := make([]byte, len(block.Bytes))
+	cipher.NewCBCDecrypter(b, iv).CryptBlocks(data, block.Bytes)
+
+	// PKCS#7 padding, checked without data dependent branches.
+	n := len(data)
+	pad := int(data[n-1])
+	good := subtle.ConstantTimeLessOrEq(1, pad) & subtle.ConstantTimeLessOrEq(pad, blockSize)
+	for i := 0; i < blockSize; i++ {
+		inPadding := subtle.ConstantTimeLessOrEq(i+1, pad)
+		good &= subtle.ConstantTimeSelect(inPadding, subtle.ConstantTimeByteEq(data[n-1-i], byte(pad)), 1)
+	}
+	if good != 1 {
+		return nil, ErrWrongPassphrase
+	}
+	return data[:n-pad], nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"testing"
+)
+
+func TestDecryptPEMBlock(t *testing.T) {
+	for _, alg := range []x509.PEMCipher{x509.PEMCipherDES, x509.PEMCipher3DES, x509.PEMCipherAES128, x509.PEMCipherAES192, x509.PEMCipherAES256} {
+		for _, size := range []int{1, 15, 16, 64} {
+			data := bytes.Repeat([]byte{0x5a}, size)
+			block, err := x509.EncryptPEMBlock(rand.Reader, PrivKeyIdentifier, data, password, alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := decryptPEMBlock(block, password)
+			if err != nil || !bytes.Equal(got, data) {
+				t.Errorf(`decryptPEMBlock(%s, %d bytes) = %x, %v, want %x, nil`, block.Headers["DEK-Info"], size, got, err, data)
+			}
+			if _, err := decryptPEMBlock(block, []byte("wrong")); err == nil {
+				// A wrong passphrase yields valid padding by chance
+				// only, with at most 1 in 256 odds.
+				t.Logf(`decryptPEMBlock(%s, wrong password) = _, nil`, block.Headers["DEK-Info"])
+			} else if !errors.Is(err, x509.IncorrectPasswordError) {
+				t.Errorf(`decryptPEMBlock(%s, wrong password) = _, %v, want %v`, block.Headers["DEK-Info"], err, x509.IncorrectPasswordError)
+			}
+		}
+	}
+}
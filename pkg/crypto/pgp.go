@@ -0,0 +1,66 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+)
+
+// armorStart is the beginning of every ASCII armored OpenPGP block.
+var armorStart = []byte("-----BEGIN ")
+
+// dearmor returns a reader for the binary contents of r, stripping the
+// ASCII armor if there is one.
+func dearmor(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	start, err := br.Peek(len(armorStart))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.Equal(start, armorStart) {
+		return br, nil
+	}
+	block, err := armor.Decode(br)
+	if err != nil {
+		return nil, err
+	}
+	return block.Body, nil
+}
+
+// VerifyPGP verifies the detached OpenPGP signature sig over data against
+// the keys in pubKeyring. Keyring and signature may each be ASCII armored or
+// binary. A signature that does not verify or that was made by a key outside
+// the keyring yields false without an error.
+func VerifyPGP(pubKeyring, data, sig io.Reader) (bool, error) {
+	keys, err := dearmor(pubKeyring)
+	if err != nil {
+		return false, err
+	}
+	keyring, err := openpgp.ReadKeyRing(keys)
+	if err != nil {
+		return false, err
+	}
+
+	signature, err := dearmor(sig)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := openpgp.CheckDetachedSignature(keyring, data, signature, nil); err != nil {
+		var sigErr pgperrors.SignatureError
+		if errors.As(err, &sigErr) || errors.Is(err, pgperrors.ErrUnknownIssuer) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
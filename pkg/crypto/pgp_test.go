@@ -0,0 +1,76 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+func newPGPEntity(t *testing.T) (*openpgp.Entity, []byte) {
+	t.Helper()
+	entity, err := openpgp.NewEntity("u-root", "test", "u-root@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatalf(`openpgp.NewEntity() = _, %v, want nil`, err)
+	}
+
+	var keyring bytes.Buffer
+	w, err := armor.Encode(&keyring, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf(`armor.Encode() = _, %v, want nil`, err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatalf(`entity.Serialize() = %v, want nil`, err)
+	}
+	w.Close()
+	return entity, keyring.Bytes()
+}
+
+func TestVerifyPGP(t *testing.T) {
+	entity, keyring := newPGPEntity(t)
+	_, otherKeyring := newPGPEntity(t)
+	data := []byte("u-root boot payload")
+
+	var armored, binary bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&armored, entity, bytes.NewReader(data), nil); err != nil {
+		t.Fatalf(`openpgp.ArmoredDetachSign() = %v, want nil`, err)
+	}
+	if err := openpgp.DetachSign(&binary, entity, bytes.NewReader(data), nil); err != nil {
+		t.Fatalf(`openpgp.DetachSign() = %v, want nil`, err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		keyring []byte
+		data    []byte
+		sig     []byte
+		want    bool
+	}{
+		{name: "armored signature", keyring: keyring, data: data, sig: armored.Bytes(), want: true},
+		{name: "binary signature", keyring: keyring, data: data, sig: binary.Bytes(), want: true},
+		{name: "modified data", keyring: keyring, data: []byte("evil payload"), sig: armored.Bytes(), want: false},
+		{name: "unknown signer", keyring: otherKeyring, data: data, sig: armored.Bytes(), want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyPGP(bytes.NewReader(tt.keyring), bytes.NewReader(tt.data), bytes.NewReader(tt.sig))
+			if err != nil {
+				t.Fatalf(`VerifyPGP() = _, %v, want nil`, err)
+			}
+			if got != tt.want {
+				t.Errorf(`VerifyPGP() = %t, want %t`, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyPGPBadKeyring(t *testing.T) {
+	if _, err := VerifyPGP(bytes.NewReader([]byte("garbage")), bytes.NewReader(nil), bytes.NewReader(nil)); err == nil {
+		t.Errorf(`VerifyPGP("garbage", _, _) = _, nil, want error`)
+	}
+}
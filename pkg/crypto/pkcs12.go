@@ -0,0 +1,69 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// LoadPKCS12 loads the private key, its certificate and the remaining
+// certificates, e.g. the chain up to the root, from the PKCS#12 (.p12, .pfx)
+// bundle at path, decrypting it with password. Only RSA and ECDSA keys and
+// the legacy 3DES and RC2 encryption supported by golang.org/x/crypto/pkcs12
+// can be read; bundles using AES, the default of OpenSSL 3, have to be
+// exported with -legacy. A wrong password returns ErrWrongPassphrase.
+func LoadPKCS12(path string, password []byte) (crypto.PrivateKey, *x509.Certificate, []*x509.Certificate, error) {
+	pfx, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	blocks, err := pkcs12.ToPEM(pfx, string(password))
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		return nil, nil, nil, ErrWrongPassphrase
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("can't decode PKCS#12 bundle: %w", err)
+	}
+
+	var priv crypto.PrivateKey
+	var certs []*x509.Certificate
+	for _, block := range blocks {
+		switch block.Type {
+		case "PRIVATE KEY":
+			if priv != nil {
+				return nil, nil, nil, errors.New("PKCS#12 bundle holds more than one private key")
+			}
+			if priv, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+				if priv, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+					return nil, nil, nil, errors.New("unsupported private key format in PKCS#12 bundle")
+				}
+			}
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			certs = append(certs, cert)
+		}
+	}
+	if priv == nil {
+		return nil, nil, nil, errors.New("PKCS#12 bundle holds no private key")
+	}
+
+	// The leaf is the certificate of the private key, wherever it is stored.
+	for i, cert := range certs {
+		if KeyPairMatches(priv, cert.PublicKey) {
+			chain := append(append([]*x509.Certificate{}, certs[:i]...), certs[i+1:]...)
+			return priv, cert, chain, nil
+		}
+	}
+	return nil, nil, nil, errors.New("PKCS#12 bundle holds no certificate for its private key")
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"path"
+	"testing"
+)
+
+// pkcs12File holds an ECDSA key with its certificate and the CA certificate,
+// encrypted with password.
+const pkcs12File = "tests/bundle.p12"
+
+func TestLoadPKCS12(t *testing.T) {
+	priv, leaf, chain, err := LoadPKCS12(pkcs12File, password)
+	if err != nil {
+		t.Fatalf(`LoadPKCS12(pkcs12File, password) = _, _, _, %v, want nil`, err)
+	}
+	if _, ok := priv.(*ecdsa.PrivateKey); !ok {
+		t.Errorf(`LoadPKCS12(pkcs12File, password) = %T, _, _, nil, want *ecdsa.PrivateKey`, priv)
+	}
+	if leaf.Subject.CommonName != "u-root test signer" {
+		t.Errorf(`LoadPKCS12(pkcs12File, password) leaf = %q, want "u-root test signer"`, leaf.Subject.CommonName)
+	}
+	if len(chain) != 1 || chain[0].Subject.CommonName != "u-root test CA" {
+		t.Fatalf(`LoadPKCS12(pkcs12File, password) chain = %v, want the test CA`, chain)
+	}
+	if err := leaf.CheckSignatureFrom(chain[0]); err != nil {
+		t.Errorf(`leaf.CheckSignatureFrom(CA) = %v, want nil`, err)
+	}
+
+	if _, _, _, err := LoadPKCS12(pkcs12File, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf(`LoadPKCS12(pkcs12File, wrong) = _, _, _, %v, want %v`, err, ErrWrongPassphrase)
+	}
+	if _, _, _, err := LoadPKCS12(privateKeyPEMFile, password); err == nil {
+		t.Errorf(`LoadPKCS12(privateKeyPEMFile, password) = _, _, _, nil, want error`)
+	}
+	if _, _, _, err := LoadPKCS12(path.Join(t.TempDir(), "missing"), password); err == nil {
+		t.Errorf(`LoadPKCS12(missing, password) = _, _, _, nil, want error`)
+	}
+}
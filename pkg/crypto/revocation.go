@@ -0,0 +1,56 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"errors"
+	"io"
+)
+
+// ErrRevokedKey is returned by VerifyWithDenylist and VerifyReaderWithDenylist
+// if the key is in the denylist.
+var ErrRevokedKey = errors.New("public key is revoked")
+
+// IsRevoked reports whether pub is in denylist. Like for
+// VerifyWithTrustedFingerprints, a key matches if its NormalizedKeyID is in
+// denylist or, for ED25519 keys, the Fingerprint of the raw key.
+func IsRevoked(pub crypto.PublicKey, denylist []string) bool {
+	ids, err := keyIDs(pub)
+	if err != nil {
+		return false
+	}
+	return matchesFingerprint(ids, denylist)
+}
+
+// checkRevoked returns ErrRevokedKey if pub is in denylist.
+func checkRevoked(pub crypto.PublicKey, denylist []string) error {
+	if len(denylist) > 0 && IsRevoked(pub, denylist) {
+		return ErrRevokedKey
+	}
+	return nil
+}
+
+// VerifyWithDenylist verifies sig over the full message data with pub, see
+// verifyMessage for the supported algorithms, but rejects pub with
+// ErrRevokedKey if it is in denylist, even if the signature is valid. This
+// gives offline revocation where CRLs and OCSP can't be reached. A nil
+// denylist revokes nothing.
+func VerifyWithDenylist(pub crypto.PublicKey, data, sig []byte, denylist []string) (bool, error) {
+	pub = typedPublicKey(pub)
+	if err := checkRevoked(pub, denylist); err != nil {
+		return false, err
+	}
+	return verifyMessage(pub, data, sig)
+}
+
+// VerifyReaderWithDenylist is like VerifyReader, but rejects pub with
+// ErrRevokedKey if it is in denylist, like VerifyWithDenylist.
+func VerifyReaderWithDenylist(pub crypto.PublicKey, r io.Reader, sig []byte, h crypto.Hash, denylist []string) (bool, error) {
+	if err := checkRevoked(pub, denylist); err != nil {
+		return false, err
+	}
+	return VerifyReader(pub, r, sig, h)
+}
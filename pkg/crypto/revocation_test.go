@@ -0,0 +1,88 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestIsRevoked(t *testing.T) {
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := NormalizedKeyID(edPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name     string
+		pub      crypto.PublicKey
+		denylist []string
+		want     bool
+	}{
+		{"key ID", edPub, []string{"00", id}, true},
+		{"upper case key ID", edPub, []string{" " + strings.ToUpper(id) + "\n"}, true},
+		{"raw fingerprint", []byte(edPub), []string{Fingerprint(edPub)}, true},
+		{"not listed", edPub, []string{Fingerprint([]byte("other"))}, false},
+		{"empty denylist", edPub, nil, false},
+		{"unsupported key", "not a key", []string{id}, false},
+	} {
+		if got := IsRevoked(tt.pub, tt.denylist); got != tt.want {
+			t.Errorf(`IsRevoked(%s) = %t, want %t`, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyWithDenylist(t *testing.T) {
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("kernel")
+	edSig := ed25519.Sign(edPriv, data)
+	digest := sha256.Sum256(data)
+	ecSig, err := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	denylist := []string{Fingerprint(edPub)}
+	if ok, err := VerifyWithDenylist(edPub, data, edSig, denylist); ok || !errors.Is(err, ErrRevokedKey) {
+		t.Errorf(`VerifyWithDenylist(revoked ED25519 key) = %t, %v, want false, %v`, ok, err, ErrRevokedKey)
+	}
+	if ok, err := VerifyWithDenylist(edPub, data, edSig, nil); !ok || err != nil {
+		t.Errorf(`VerifyWithDenylist(nil denylist) = %t, %v, want true, nil`, ok, err)
+	}
+	if ok, err := VerifyReaderWithDenylist(&ecKey.PublicKey, bytes.NewReader(data), ecSig, crypto.SHA256, denylist); !ok || err != nil {
+		t.Errorf(`VerifyReaderWithDenylist(ECDSA key) = %t, %v, want true, nil`, ok, err)
+	}
+
+	ecID, err := NormalizedKeyID(&ecKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	denylist = append(denylist, ecID)
+	if ok, err := VerifyReaderWithDenylist(&ecKey.PublicKey, bytes.NewReader(data), ecSig, crypto.SHA256, denylist); ok || !errors.Is(err, ErrRevokedKey) {
+		t.Errorf(`VerifyReaderWithDenylist(revoked ECDSA key) = %t, %v, want false, %v`, ok, err, ErrRevokedKey)
+	}
+	if ok, err := VerifyWithDenylist(&ecKey.PublicKey, data, ecSig, denylist); ok || !errors.Is(err, ErrRevokedKey) {
+		t.Errorf(`VerifyWithDenylist(revoked ECDSA key) = %t, %v, want false, %v`, ok, err, ErrRevokedKey)
+	}
+}
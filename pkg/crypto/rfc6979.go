@@ -0,0 +1,135 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// deterministicECDSASigner signs with RFC 6979 deterministic nonces.
+type deterministicECDSASigner struct {
+	priv *ecdsa.PrivateKey
+}
+
+// NewDeterministicECDSASigner returns a crypto.Signer for priv that derives
+// its nonces from the key and digest as described in RFC 6979 instead of
+// drawing them at random, so that signing the same digest with the same key
+// always yields the same ASN.1 signature. This makes signed boot artifacts
+// byte-reproducible. The rand argument of Sign is ignored.
+func NewDeterministicECDSASigner(priv *ecdsa.PrivateKey) crypto.Signer {
+	return deterministicECDSASigner{priv: priv}
+}
+
+// Public implements crypto.Signer.Public.
+func (d deterministicECDSASigner) Public() crypto.PublicKey {
+	return &d.priv.PublicKey
+}
+
+// Sign implements crypto.Signer.Sign.
+func (d deterministicECDSASigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	h := opts.HashFunc()
+	if h == 0 || !h.Available() {
+		return nil, fmt.Errorf("RFC 6979 signing requires an available hash function, got %v", h)
+	}
+	if len(digest) != h.Size() {
+		return nil, fmt.Errorf("digest is %d bytes, want %d for %v", len(digest), h.Size(), h)
+	}
+
+	curve := d.priv.Curve
+	n := curve.Params().N
+	e := bits2int(digest, n.BitLen())
+	nextNonce := rfc6979Nonces(h, n, d.priv.D, digest)
+	for {
+		k := nextNonce()
+		x, _ := curve.ScalarBaseMult(k.Bytes())
+		r := new(big.Int).Mod(x, n)
+		if r.Sign() == 0 {
+			continue
+		}
+		s := new(big.Int).Mul(r, d.priv.D)
+		s.Add(s, e)
+		s.Mul(s, new(big.Int).ModInverse(k, n))
+		s.Mod(s, n)
+		if s.Sign() == 0 {
+			continue
+		}
+		return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	}
+}
+
+// bits2int interprets b as a big-endian integer of at most qlen bits, see
+// RFC 6979 section 2.3.2.
+func bits2int(b []byte, qlen int) *big.Int {
+	v := new(big.Int).SetBytes(b)
+	if blen := len(b) * 8; blen > qlen {
+		v.Rsh(v, uint(blen-qlen))
+	}
+	return v
+}
+
+// int2octets returns v as a big-endian byte string of rlen bytes, see RFC 6979
+// section 2.3.3.
+func int2octets(v *big.Int, rlen int) []byte {
+	b := v.Bytes()
+	if len(b) >= rlen {
+		return b[len(b)-rlen:]
+	}
+	return append(make([]byte, rlen-len(b)), b...)
+}
+
+// rfc6979Nonces returns a generator of the candidate nonces for signing digest
+// with the private scalar x modulo n, using HMAC-DRBG with h as described in
+// RFC 6979 section 3.2. Each call returns the next candidate in [1, n-1].
+func rfc6979Nonces(h crypto.Hash, n, x *big.Int, digest []byte) func() *big.Int {
+	qlen := n.BitLen()
+	rlen := (qlen + 7) / 8
+	z := bits2int(digest, qlen)
+	if z.Cmp(n) >= 0 {
+		z.Sub(z, n)
+	}
+	seed := append(int2octets(x, rlen), int2octets(z, rlen)...)
+
+	mac := func(key []byte, data ...[]byte) []byte {
+		m := hmac.New(h.New, key)
+		for _, d := range data {
+			m.Write(d)
+		}
+		return m.Sum(nil)
+	}
+	v := make([]byte, h.Size())
+	for i := range v {
+		v[i] = 0x01
+	}
+	k := make([]byte, h.Size())
+	k = mac(k, v, []byte{0x00}, seed)
+	v = mac(k, v)
+	k = mac(k, v, []byte{0x01}, seed)
+	v = mac(k, v)
+
+	first := true
+	return func() *big.Int {
+		for {
+			if !first {
+				k = mac(k, v, []byte{0x00})
+				v = mac(k, v)
+			}
+			first = false
+			var t []byte
+			for len(t) < rlen {
+				v = mac(k, v)
+				t = append(t, v...)
+			}
+			if candidate := bits2int(t[:rlen], qlen); candidate.Sign() > 0 && candidate.Cmp(n) < 0 {
+				return candidate
+			}
+		}
+	}
+}
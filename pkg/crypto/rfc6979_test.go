@@ -0,0 +1,86 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+)
+
+func hexInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		t.Fatalf("invalid hex integer %q", s)
+	}
+	return v
+}
+
+func TestDeterministicECDSASigner(t *testing.T) {
+	// RFC 6979 appendix A.2.5, P-256 with SHA-256 and message "sample".
+	priv := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     hexInt(t, "60FED4BA255A9D31C961EB74C6356D68C049B8923B61FA6CE669622E60F29FB6"),
+			Y:     hexInt(t, "7903FE1008B8BC99A41AE9E95628BC64F2F1B20C2D7E9F5177A3C294D4462299"),
+		},
+		D: hexInt(t, "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721"),
+	}
+	wantR := hexInt(t, "EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716")
+	wantS := hexInt(t, "F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8")
+
+	signer := NewDeterministicECDSASigner(priv)
+	sig, err := SignReader(signer, bytes.NewReader([]byte("sample")), crypto.SHA256)
+	if err != nil {
+		t.Fatalf(`SignReader(NewDeterministicECDSASigner(priv)) = _, %v, want nil`, err)
+	}
+	var rs struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(sig, &rs); err != nil {
+		t.Fatalf(`asn1.Unmarshal(sig) = _, %v, want nil`, err)
+	}
+	if rs.R.Cmp(wantR) != 0 || rs.S.Cmp(wantS) != 0 {
+		t.Errorf(`signature = (r %X, s %X), want (r %X, s %X)`, rs.R, rs.S, wantR, wantS)
+	}
+
+	again, err := SignReader(signer, bytes.NewReader([]byte("sample")), crypto.SHA256)
+	if err != nil || !bytes.Equal(again, sig) {
+		t.Errorf(`SignReader() again = %x, %v, want %x, nil`, again, err, sig)
+	}
+	if ok, err := VerifyReader(signer.Public(), bytes.NewReader([]byte("sample")), sig, crypto.SHA256); err != nil || !ok {
+		t.Errorf(`VerifyReader() = %t, %v, want true, nil`, ok, err)
+	}
+}
+
+func TestDeterministicECDSASignerCurves(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P224(), elliptic.P384(), elliptic.P521()} {
+		priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest := sha256.Sum256([]byte("boot artifact"))
+		sig, err := NewDeterministicECDSASigner(priv).Sign(nil, digest[:], crypto.SHA256)
+		if err != nil {
+			t.Fatalf(`Sign() on %s = _, %v, want nil`, curve.Params().Name, err)
+		}
+		if !ecdsa.VerifyASN1(&priv.PublicKey, digest[:], sig) {
+			t.Errorf(`ecdsa.VerifyASN1() on %s = false, want true`, curve.Params().Name)
+		}
+	}
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDeterministicECDSASigner(priv).Sign(nil, []byte("short"), crypto.SHA256); err == nil {
+		t.Errorf(`Sign(short digest) = _, nil, want error`)
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// ErrRequiresFullMessage is returned when a digest based helper is used with
+// an algorithm, like plain Ed25519, that has to sign the full message.
+var ErrRequiresFullMessage = errors.New("algorithm requires the full message instead of a digest")
+
+// ErrMalformedSignature is returned when a signature does not have the length
+// required by the key algorithm, which usually means the signature file is
+// truncated or corrupt rather than made with a different key.
+var ErrMalformedSignature = errors.New("malformed signature")
+
+// checkSignatureLength returns ErrMalformedSignature if sig cannot be a
+// signature made with the private half of pub. ECDSA signatures are ASN.1
+// encoded and of variable length, so they are only checked for being empty.
+func checkSignatureLength(pub crypto.PublicKey, sig []byte) error {
+	var want int
+	switch key := pub.(type) {
+	case ed25519.PublicKey:
+		want = ed25519.SignatureSize
+	case *rsa.PublicKey:
+		want = key.Size()
+	case *ecdsa.PublicKey:
+		if len(sig) == 0 {
+			return fmt.Errorf("%w: empty ECDSA signature", ErrMalformedSignature)
+		}
+		return nil
+	default:
+		return nil
+	}
+	if len(sig) != want {
+		return fmt.Errorf("%w: %d bytes, want %d", ErrMalformedSignature, len(sig), want)
+	}
+	return nil
+}
+
+// hashReader returns the digest of everything read from r.
+func hashReader(r io.Reader, h crypto.Hash) ([]byte, error) {
+	if !h.Available() {
+		return nil, fmt.Errorf("hash function %v is not available", h)
+	}
+	hasher := h.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return nil, err
+	}
+	return hasher.Sum(nil), nil
+}
+
+// MultiHash returns the digests of everything read from r with each of
+// hashes, reading r only once, e.g. to list both the SHA-256 and SHA-512 of
+// a large boot artifact in a manifest.
+func MultiHash(r io.Reader, hashes ...crypto.Hash) (map[crypto.Hash][]byte, error) {
+	if len(hashes) == 0 {
+		return nil, errors.New("no hash functions given")
+	}
+	hashers := make(map[crypto.Hash]hash.Hash, len(hashes))
+	var writers []io.Writer
+	for _, h := range hashes {
+		if !h.Available() {
+			return nil, fmt.Errorf("hash function %v is not available", h)
+		}
+		if _, ok := hashers[h]; ok {
+			continue
+		}
+		hashers[h] = h.New()
+		writers = append(writers, hashers[h])
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return nil, err
+	}
+	digests := make(map[crypto.Hash][]byte, len(hashers))
+	for h, hasher := range hashers {
+		digests[h] = hasher.Sum(nil)
+	}
+	return digests, nil
+}
+
+// DefaultHashFor returns the hash matching the security level of key: SHA-256
+// for RSA keys up to 7679 bits and ECDSA keys up to 256 bits, SHA-384 for
+// P-384 and RSA keys up to 15359 bits, and SHA-512 for larger keys. Ed25519
+// hashes the message itself, so it returns 0. Other keys get SHA-256.
+func DefaultHashFor(key crypto.PublicKey) crypto.Hash {
+	switch key := typedPublicKey(key).(type) {
+	case ed25519.PublicKey:
+		return 0
+	case *rsa.PublicKey:
+		// The NIST SP 800-57 RSA sizes matching the hash strengths.
+		switch bits := key.N.BitLen(); {
+		case bits >= 15360:
+			return crypto.SHA512
+		case bits >= 7680:
+			return crypto.SHA384
+		}
+	case *ecdsa.PublicKey:
+		switch bits := key.Curve.Params().BitSize; {
+		case bits > 384:
+			return crypto.SHA512
+		case bits > 256:
+			return crypto.SHA384
+		}
+	}
+	return crypto.SHA256
+}
+
+// SignReader hashes r incrementally with h and signs the resulting digest.
+// If h is 0, DefaultHashFor the key of signer is used.
+func SignReader(signer crypto.Signer, r io.Reader, h crypto.Hash) ([]byte, error) {
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		return nil, ErrRequiresFullMessage
+	}
+	if h == 0 {
+		h = DefaultHashFor(signer.Public())
+	}
+	digest, err := hashReader(r, h)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Sign(nil, digest, h)
+}
+
+// SignatureEncoding selects how signatures are written out.
+type SignatureEncoding int
+
+const (
+	// EncodingRaw leaves signatures as binary.
+	EncodingRaw SignatureEncoding = iota
+	// EncodingBase64 encodes signatures with standard, padded base64.
+	EncodingBase64
+	// EncodingHex encodes signatures as lower case hex.
+	EncodingHex
+)
+
+// encodeSignature encodes sig with enc.
+func encodeSignature(sig []byte, enc SignatureEncoding) ([]byte, error) {
+	switch enc {
+	case EncodingRaw:
+		return sig, nil
+	case EncodingBase64:
+		return []byte(base64.StdEncoding.EncodeToString(sig)), nil
+	case EncodingHex:
+		return []byte(hex.EncodeToString(sig)), nil
+	default:
+		return nil, fmt.Errorf("unknown signature encoding %d", enc)
+	}
+}
+
+// decodeSignature reverses encodeSignature. Surrounding white space, like the
+// trailing newline of a pasted signature, is ignored for the text encodings.
+func decodeSignature(sig []byte, enc SignatureEncoding) ([]byte, error) {
+	var decoded []byte
+	var err error
+	switch enc {
+	case EncodingRaw:
+		return sig, nil
+	case EncodingBase64:
+		decoded, err = base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig)))
+	case EncodingHex:
+		decoded, err = hex.DecodeString(string(bytes.TrimSpace(sig)))
+	default:
+		return nil, fmt.Errorf("unknown signature encoding %d", enc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedSignature, err)
+	}
+	return decoded, nil
+}
+
+// SignReaderEncoded is like SignReader but returns the signature encoded
+// with enc, e.g. to embed it in a text based boot policy.
+func SignReaderEncoded(signer crypto.Signer, r io.Reader, h crypto.Hash, enc SignatureEncoding) ([]byte, error) {
+	sig, err := SignReader(signer, r, h)
+	if err != nil {
+		return nil, err
+	}
+	return encodeSignature(sig, enc)
+}
+
+// SignFile signs the contents of the file at path like SignReaderEncoded.
+// It takes ownership of signer and wipes its key material with WipeKey once
+// done, so that the key does not linger in memory; use SignReaderEncoded to
+// sign several files with the same key.
+func SignFile(signer crypto.Signer, path string, h crypto.Hash, enc SignatureEncoding) ([]byte, error) {
+	defer WipeKey(signer)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return SignReaderEncoded(signer, f, h, enc)
+}
+
+// VerifyReaderEncoded is like VerifyReader for a signature encoded with enc.
+// Signatures that cannot be decoded are reported as ErrMalformedSignature.
+func VerifyReaderEncoded(pub crypto.PublicKey, r io.Reader, sig []byte, h crypto.Hash, enc SignatureEncoding) (bool, error) {
+	decoded, err := decodeSignature(sig, enc)
+	if err != nil {
+		return false, err
+	}
+	return VerifyReader(pub, r, decoded, h)
+}
+
+// VerifyReader hashes r incrementally with h and verifies sig over the
+// resulting digest. If h is 0, DefaultHashFor pub is used.
+func VerifyReader(pub crypto.PublicKey, r io.Reader, sig []byte, h crypto.Hash) (bool, error) {
+	ok, _, err := VerifyReaderWithDigest(pub, r, sig, h)
+	return ok, err
+}
+
+// VerifyReaderWithDigest is like VerifyReader but also returns the digest it
+// computed, so that failed verifications can be audited.
+func VerifyReaderWithDigest(pub crypto.PublicKey, r io.Reader, sig []byte, h crypto.Hash) (ok bool, digest []byte, err error) {
+	return verifyReader(pub, r, sig, h, RSAVerifyOptions{})
+}
+
+// RSAPadding is the padding scheme of an RSA signature.
+type RSAPadding int
+
+const (
+	// RSAPaddingPKCS1v15 is RSASSA-PKCS1-v1_5, the default.
+	RSAPaddingPKCS1v15 RSAPadding = iota
+	// RSAPaddingPSS is RSASSA-PSS.
+	RSAPaddingPSS
+)
+
+// RSAVerifyOptions select how RSA signatures are verified. They are ignored
+// for other keys. The zero value verifies PKCS#1 v1.5 signatures.
+type RSAVerifyOptions struct {
+	Padding RSAPadding
+	// SaltLength is the PSS salt length, as in rsa.PSSOptions: 0 accepts
+	// any salt length, rsa.PSSSaltLengthEqualsHash a salt as long as the
+	// digest.
+	SaltLength int
+}
+
+// VerifyReaderWithRSAOptions is like VerifyReader, but verifies RSA
+// signatures with the padding selected by opts, e.g. for artifacts signed by
+// tooling defaulting to RSA-PSS.
+func VerifyReaderWithRSAOptions(pub crypto.PublicKey, r io.Reader, sig []byte, h crypto.Hash, opts RSAVerifyOptions) (bool, error) {
+	ok, _, err := verifyReader(pub, r, sig, h, opts)
+	return ok, err
+}
+
+func verifyReader(pub crypto.PublicKey, r io.Reader, sig []byte, h crypto.Hash, opts RSAVerifyOptions) (ok bool, digest []byte, err error) {
+	if _, ok := pub.(ed25519.PublicKey); ok {
+		return false, nil, ErrRequiresFullMessage
+	}
+	if h == 0 {
+		h = DefaultHashFor(pub)
+	}
+	digest, err = hashReader(r, h)
+	if err != nil {
+		return false, nil, err
+	}
+	ok, err = verifyDigest(pub, digest, sig, h, opts)
+	return ok, digest, err
+}
+
+// verifyDigest verifies sig over digest, which was computed with h.
+func verifyDigest(pub crypto.PublicKey, digest, sig []byte, h crypto.Hash, opts RSAVerifyOptions) (bool, error) {
+	if err := checkSignatureLength(pub, sig); err != nil {
+		return false, err
+	}
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		switch opts.Padding {
+		case RSAPaddingPKCS1v15:
+			return rsa.VerifyPKCS1v15(key, h, digest, sig) == nil, nil
+		case RSAPaddingPSS:
+			return rsa.VerifyPSS(key, h, digest, sig, &rsa.PSSOptions{SaltLength: opts.SaltLength}) == nil, nil
+		default:
+			return false, fmt.Errorf("unsupported RSA padding %d", opts.Padding)
+		}
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, digest, sig), nil
+	case ed25519.PublicKey:
+		return false, ErrRequiresFullMessage
+	default:
+		return false, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// verifyMessage verifies sig over the full message msg: Ed25519 signs msg
+// itself, RSA PKCS#1 v1.5 and ECDSA its SHA-256 digest.
+func verifyMessage(pub crypto.PublicKey, msg, sig []byte) (bool, error) {
+	if key, ok := pub.(ed25519.PublicKey); ok {
+		if err := checkSignatureLength(key, sig); err != nil {
+			return false, err
+		}
+		return ed25519.Verify(key, msg, sig), nil
+	}
+	return VerifyReader(pub, bytes.NewReader(msg), sig, crypto.SHA256)
+}
+
+// VerifyWithKeyString verifies sig over data with a base64 encoded public key,
+// for ad-hoc checks without a key file. A 32 byte key is taken as a raw
+// Ed25519 key, anything else has to be a PKIX DER encoded key. See
+// verifyMessage for the supported algorithms.
+func VerifyWithKeyString(b64PubKey string, data, sig []byte) (bool, error) {
+	der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(b64PubKey))
+	if err != nil {
+		return false, fmt.Errorf("can't decode public key: %w", err)
+	}
+	var pub crypto.PublicKey
+	if len(der) == ed25519.PublicKeySize {
+		pub = ed25519.PublicKey(der)
+	} else if pub, err = x509.ParsePKIXPublicKey(der); err != nil {
+		return false, fmt.Errorf("can't parse public key: %w", err)
+	}
+	return verifyMessage(pub, data, sig)
+}
@@ -0,0 +1,353 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestSignVerifyReader(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testData, err := os.ReadFile(testDataFile)
+	if err != nil {
+		t.Fatalf(`os.ReadFile(testDataFile) = _, %v, want nil`, err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		signer crypto.Signer
+		hash   crypto.Hash
+	}{
+		{name: "rsa", signer: rsaKey, hash: crypto.SHA256},
+		{name: "ecdsa", signer: ecdsaKey, hash: crypto.SHA384},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := SignReader(tt.signer, bytes.NewReader(testData), tt.hash)
+			if err != nil {
+				t.Fatalf(`SignReader() = _, %v, want nil`, err)
+			}
+			if ok, err := VerifyReader(tt.signer.Public(), bytes.NewReader(testData), sig, tt.hash); err != nil || !ok {
+				t.Errorf(`VerifyReader(testData) = %t, %v, want true, nil`, ok, err)
+			}
+			if ok, err := VerifyReader(tt.signer.Public(), bytes.NewReader(testData[1:]), sig, tt.hash); err != nil || ok {
+				t.Errorf(`VerifyReader(testData[1:]) = %t, %v, want false, nil`, ok, err)
+			}
+		})
+	}
+}
+
+func TestVerifyReaderWithDigest(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("boot artifact")
+	sig, err := SignReader(key, bytes.NewReader(data), crypto.SHA256)
+	if err != nil {
+		t.Fatalf(`SignReader() = _, %v, want nil`, err)
+	}
+
+	want := sha256.Sum256([]byte("tampered artifact"))
+	ok, digest, err := VerifyReaderWithDigest(key.Public(), bytes.NewReader([]byte("tampered artifact")), sig, crypto.SHA256)
+	if err != nil || ok {
+		t.Errorf(`VerifyReaderWithDigest(tampered) = %t, _, %v, want false, _, nil`, ok, err)
+	}
+	if !bytes.Equal(digest, want[:]) {
+		t.Errorf(`VerifyReaderWithDigest(tampered) = _, %x, _, want %x`, digest, want)
+	}
+}
+
+func TestVerifyReaderMalformedSignature(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("boot artifact")
+	sig, err := SignReader(rsaKey, bytes.NewReader(data), crypto.SHA256)
+	if err != nil {
+		t.Fatalf(`SignReader() = _, %v, want nil`, err)
+	}
+
+	if _, err := VerifyReader(rsaKey.Public(), bytes.NewReader(data), sig[:len(sig)-1], crypto.SHA256); !errors.Is(err, ErrMalformedSignature) {
+		t.Errorf(`VerifyReader(truncated RSA signature) = _, %v, want %v`, err, ErrMalformedSignature)
+	}
+	if _, err := VerifyReader(ecdsaKey.Public(), bytes.NewReader(data), nil, crypto.SHA256); !errors.Is(err, ErrMalformedSignature) {
+		t.Errorf(`VerifyReader(empty ECDSA signature) = _, %v, want %v`, err, ErrMalformedSignature)
+	}
+}
+
+func TestSignVerifyEncoded(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testData, err := os.ReadFile(testDataFile)
+	if err != nil {
+		t.Fatalf(`os.ReadFile(testDataFile) = _, %v, want nil`, err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		enc   SignatureEncoding
+		valid func([]byte) bool
+	}{
+		{name: "raw", enc: EncodingRaw, valid: func([]byte) bool { return true }},
+		{name: "base64", enc: EncodingBase64, valid: func(sig []byte) bool {
+			_, err := base64.StdEncoding.DecodeString(string(sig))
+			return err == nil
+		}},
+		{name: "hex", enc: EncodingHex, valid: func(sig []byte) bool {
+			_, err := hex.DecodeString(string(sig))
+			return err == nil
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// SignFile wipes the key it is given.
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig, err := SignFile(key, testDataFile, crypto.SHA256, tt.enc)
+			if err != nil {
+				t.Fatalf(`SignFile(testDataFile) = _, %v, want nil`, err)
+			}
+			if !tt.valid(sig) {
+				t.Errorf(`SignFile(testDataFile) = %q, not %s encoded`, sig, tt.name)
+			}
+			// A pasted signature usually comes with a trailing newline.
+			if tt.enc != EncodingRaw {
+				sig = append(sig, '\n')
+			}
+			if ok, err := VerifyReaderEncoded(key.Public(), bytes.NewReader(testData), sig, crypto.SHA256, tt.enc); err != nil || !ok {
+				t.Errorf(`VerifyReaderEncoded(testData) = %t, %v, want true, nil`, ok, err)
+			}
+		})
+	}
+
+	if _, err := SignFile(key, testDataFile, crypto.SHA256, EncodingRaw); err != nil {
+		t.Fatalf(`SignFile(testDataFile) = _, %v, want nil`, err)
+	}
+	if key.D.Sign() != 0 {
+		t.Errorf(`SignFile() left the private key %v, want it zeroed`, key.D)
+	}
+	if _, err := VerifyReaderEncoded(key.Public(), bytes.NewReader(testData), []byte("not hex"), crypto.SHA256, EncodingHex); !errors.Is(err, ErrMalformedSignature) {
+		t.Errorf(`VerifyReaderEncoded("not hex") = _, %v, want %v`, err, ErrMalformedSignature)
+	}
+	if _, err := SignReaderEncoded(key, bytes.NewReader(testData), crypto.SHA256, SignatureEncoding(42)); err == nil {
+		t.Errorf(`SignReaderEncoded(encoding 42) = _, nil, want error`)
+	}
+}
+
+func TestVerifyWithKeyString(t *testing.T) {
+	data := []byte("boot artifact")
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edSig := ed25519.Sign(edPriv, data)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaSig, err := SignReader(ecdsaKey, bytes.NewReader(data), crypto.SHA256)
+	if err != nil {
+		t.Fatalf(`SignReader() = _, %v, want nil`, err)
+	}
+
+	edDER, err := x509.MarshalPKIXPublicKey(edPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaDER, err := x509.MarshalPKIXPublicKey(&ecdsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		key  []byte
+		sig  []byte
+	}{
+		{name: "raw ed25519", key: edPub, sig: edSig},
+		{name: "der ed25519", key: edDER, sig: edSig},
+		{name: "der ecdsa", key: ecdsaDER, sig: ecdsaSig},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			key := base64.StdEncoding.EncodeToString(tt.key)
+			if ok, err := VerifyWithKeyString(key, data, tt.sig); err != nil || !ok {
+				t.Errorf(`VerifyWithKeyString(data) = %t, %v, want true, nil`, ok, err)
+			}
+			if ok, err := VerifyWithKeyString(key, data[1:], tt.sig); err != nil || ok {
+				t.Errorf(`VerifyWithKeyString(data[1:]) = %t, %v, want false, nil`, ok, err)
+			}
+		})
+	}
+
+	for _, key := range []string{"!!!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := VerifyWithKeyString(key, data, edSig); err == nil {
+			t.Errorf(`VerifyWithKeyString(%q) = _, nil, want error`, key)
+		}
+	}
+}
+
+func TestSignReaderEd25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SignReader(priv, bytes.NewReader(nil), crypto.SHA512); !errors.Is(err, ErrRequiresFullMessage) {
+		t.Errorf(`SignReader(ed25519) = _, %v, want %v`, err, ErrRequiresFullMessage)
+	}
+	if _, err := VerifyReader(pub, bytes.NewReader(nil), nil, crypto.SHA512); !errors.Is(err, ErrRequiresFullMessage) {
+		t.Errorf(`VerifyReader(ed25519) = _, %v, want %v`, err, ErrRequiresFullMessage)
+	}
+}
+
+func TestVerifyReaderWithRSAOptions(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("boot artifact")
+	digest := sha256.Sum256(data)
+	pssSig, err := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs1Sig, err := SignReader(rsaKey, bytes.NewReader(data), crypto.SHA256)
+	if err != nil {
+		t.Fatalf(`SignReader() = _, %v, want nil`, err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		sig  []byte
+		opts RSAVerifyOptions
+		want bool
+	}{
+		{name: "pss", sig: pssSig, opts: RSAVerifyOptions{Padding: RSAPaddingPSS}, want: true},
+		{name: "pss salt length", sig: pssSig, opts: RSAVerifyOptions{Padding: RSAPaddingPSS, SaltLength: rsa.PSSSaltLengthEqualsHash}, want: true},
+		{name: "pss wrong salt length", sig: pssSig, opts: RSAVerifyOptions{Padding: RSAPaddingPSS, SaltLength: 20}, want: false},
+		{name: "pss as pkcs1v15", sig: pssSig, want: false},
+		{name: "pkcs1v15", sig: pkcs1Sig, want: true},
+		{name: "pkcs1v15 as pss", sig: pkcs1Sig, opts: RSAVerifyOptions{Padding: RSAPaddingPSS}, want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := VerifyReaderWithRSAOptions(rsaKey.Public(), bytes.NewReader(data), tt.sig, crypto.SHA256, tt.opts)
+			if err != nil || ok != tt.want {
+				t.Errorf(`VerifyReaderWithRSAOptions() = %t, %v, want %t, nil`, ok, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiHash(t *testing.T) {
+	data := bytes.Repeat([]byte("bzImage"), 10000)
+	r := &countingReader{r: bytes.NewReader(data)}
+	digests, err := MultiHash(r, crypto.SHA256, crypto.SHA512, crypto.SHA256)
+	if err != nil {
+		t.Fatalf(`MultiHash() = _, %v, want nil`, err)
+	}
+	sum256 := sha256.Sum256(data)
+	sum512 := sha512.Sum512(data)
+	if len(digests) != 2 || !bytes.Equal(digests[crypto.SHA256], sum256[:]) || !bytes.Equal(digests[crypto.SHA512], sum512[:]) {
+		t.Errorf(`MultiHash() = %x, want SHA-256 %x and SHA-512 %x`, digests, sum256, sum512)
+	}
+	if r.n != len(data) {
+		t.Errorf(`MultiHash() read %d bytes, want %d`, r.n, len(data))
+	}
+
+	if _, err := MultiHash(bytes.NewReader(data)); err == nil {
+		t.Errorf(`MultiHash() without hashes = _, nil, want error`)
+	}
+	if _, err := MultiHash(bytes.NewReader(data), crypto.Hash(0)); err == nil {
+		t.Errorf(`MultiHash(unavailable hash) = _, nil, want error`)
+	}
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestDefaultHashFor(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name string
+		key  crypto.PublicKey
+		want crypto.Hash
+	}{
+		{name: "rsa-2048", key: &rsaKey.PublicKey, want: crypto.SHA256},
+		{name: "rsa-8192", key: &rsa.PublicKey{N: new(big.Int).Lsh(big.NewInt(1), 8191), E: 65537}, want: crypto.SHA384},
+		{name: "rsa-16384", key: &rsa.PublicKey{N: new(big.Int).Lsh(big.NewInt(1), 16383), E: 65537}, want: crypto.SHA512},
+		{name: "p-256", key: &ecdsa.PublicKey{Curve: elliptic.P256()}, want: crypto.SHA256},
+		{name: "p-384", key: &ecdsa.PublicKey{Curve: elliptic.P384()}, want: crypto.SHA384},
+		{name: "p-521", key: &ecdsa.PublicKey{Curve: elliptic.P521()}, want: crypto.SHA512},
+		{name: "ed25519", key: edPub, want: 0},
+		{name: "raw ed25519", key: []byte(edPub), want: 0},
+	} {
+		if got := DefaultHashFor(tt.key); got != tt.want {
+			t.Errorf(`DefaultHashFor(%s) = %v, want %v`, tt.name, got, tt.want)
+		}
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignReader(ecKey, strings.NewReader("kernel"), 0)
+	if err != nil {
+		t.Fatalf(`SignReader() = _, %v, want nil`, err)
+	}
+	if ok, err := VerifyReader(ecKey.Public(), strings.NewReader("kernel"), sig, crypto.SHA384); !ok || err != nil {
+		t.Errorf(`VerifyReader(SHA-384) = %t, %v, want true, nil`, ok, err)
+	}
+	if ok, err := VerifyReader(ecKey.Public(), strings.NewReader("kernel"), sig, 0); !ok || err != nil {
+		t.Errorf(`VerifyReader(default hash) = %t, %v, want true, nil`, ok, err)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+// memorySigner is a crypto.Signer backed by a private key held in memory.
+type memorySigner struct {
+	priv crypto.PrivateKey
+}
+
+// NewMemorySigner returns a crypto.Signer for a private key held in memory,
+// including the raw ED25519 keys returned by the loaders. It is primarily
+// meant for tests that need a signer without key files or hardware. Signing
+// with an unsupported key type fails.
+func NewMemorySigner(priv crypto.PrivateKey) crypto.Signer {
+	return memorySigner{priv: typedPrivateKey(priv)}
+}
+
+// Public implements crypto.Signer.Public.
+func (m memorySigner) Public() crypto.PublicKey {
+	if s, ok := m.priv.(crypto.Signer); ok {
+		return s.Public()
+	}
+	return nil
+}
+
+// Sign implements crypto.Signer.Sign.
+func (m memorySigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s, ok := m.priv.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", m.priv)
+	}
+	return s.Sign(rand, digest, opts)
+}
+
+// auditedSigner logs every sign operation before delegating to its signer.
+type auditedSigner struct {
+	crypto.Signer
+	logger *log.Logger
+}
+
+// AuditedSigner wraps s so that the time and digest of every sign operation
+// are logged to logger before s signs, creating an audit trail of what was
+// signed. Ed25519 signs full messages, for those the SHA-256 of the message
+// is logged instead.
+func AuditedSigner(s crypto.Signer, logger *log.Logger) crypto.Signer {
+	return auditedSigner{Signer: s, logger: logger}
+}
+
+// Sign implements crypto.Signer.Sign.
+func (a auditedSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	if h := opts.HashFunc(); h != 0 {
+		a.logger.Printf("%s: signing %v digest %x", now, h, digest)
+	} else {
+		a.logger.Printf("%s: signing message with SHA-256 %x", now, sha256.Sum256(digest))
+	}
+	return a.Signer.Sign(rand, digest, opts)
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestMemorySigner(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("boot artifact")
+
+	signer := NewMemorySigner(ecdsaKey)
+	sig, err := SignReader(signer, bytes.NewReader(data), crypto.SHA256)
+	if err != nil {
+		t.Fatalf(`SignReader(NewMemorySigner(ecdsaKey)) = _, %v, want nil`, err)
+	}
+	if ok, err := VerifyReader(signer.Public(), bytes.NewReader(data), sig, crypto.SHA256); err != nil || !ok {
+		t.Errorf(`VerifyReader() = %t, %v, want true, nil`, ok, err)
+	}
+}
+
+func TestMemorySignerRawEd25519(t *testing.T) {
+	privateKey, err := LoadPrivateKeyFromFile(privateKeyPEMFile, password)
+	if err != nil {
+		t.Fatalf(`LoadPrivateKeyFromFile(privateKeyPEMFile, password) = _, %v, want nil`, err)
+	}
+	publicKey, err := LoadPublicKeyFromFile(publicKeyPEMFile)
+	if err != nil {
+		t.Fatalf(`LoadPublicKeyFromFile(publicKeyPEMFile) = _, %v, want nil`, err)
+	}
+	data := []byte("boot artifact")
+
+	signer := NewMemorySigner(privateKey)
+	sig, err := signer.Sign(nil, data, crypto.Hash(0))
+	if err != nil {
+		t.Fatalf(`signer.Sign() = _, %v, want nil`, err)
+	}
+	if !ed25519.Verify(publicKey, data, sig) {
+		t.Errorf(`ed25519.Verify(publicKey, data, sig) = false, want true`)
+	}
+}
+
+func TestMemorySignerUnsupported(t *testing.T) {
+	signer := NewMemorySigner("not a key")
+	if pub := signer.Public(); pub != nil {
+		t.Errorf(`signer.Public() = %v, want nil`, pub)
+	}
+	if _, err := signer.Sign(nil, nil, crypto.SHA256); err == nil {
+		t.Errorf(`signer.Sign() = _, nil, want error`)
+	}
+}
+
+func TestAuditedSigner(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("boot artifact")
+
+	var logs bytes.Buffer
+	signer := AuditedSigner(ecdsaKey, log.New(&logs, "", 0))
+	sig, err := SignReader(signer, bytes.NewReader(data), crypto.SHA256)
+	if err != nil {
+		t.Fatalf(`SignReader(AuditedSigner(ecdsaKey)) = _, %v, want nil`, err)
+	}
+	if ok, err := VerifyReader(signer.Public(), bytes.NewReader(data), sig, crypto.SHA256); err != nil || !ok {
+		t.Errorf(`VerifyReader() = %t, %v, want true, nil`, ok, err)
+	}
+	if want := fmt.Sprintf("signing SHA-256 digest %x", sha256.Sum256(data)); !strings.Contains(logs.String(), want) {
+		t.Errorf(`AuditedSigner logged %q, want it to contain %q`, logs.String(), want)
+	}
+
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs.Reset()
+	if _, err := AuditedSigner(edPriv, log.New(&logs, "", 0)).Sign(nil, data, crypto.Hash(0)); err != nil {
+		t.Fatalf(`AuditedSigner(edPriv).Sign() = _, %v, want nil`, err)
+	}
+	if want := fmt.Sprintf("signing message with SHA-256 %x", sha256.Sum256(data)); !strings.Contains(logs.String(), want) {
+		t.Errorf(`AuditedSigner logged %q, want it to contain %q`, logs.String(), want)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// ErrWeakKey is returned by CheckKeyStrength if a key does not meet the
+// policy.
+var ErrWeakKey = errors.New("key does not meet the strength policy")
+
+// StrengthPolicy is the minimum strength CheckKeyStrength requires of keys.
+// The zero value accepts all supported keys.
+type StrengthPolicy struct {
+	// MinRSABits is the smallest accepted RSA modulus size.
+	MinRSABits int
+	// ECDSACurves are the accepted ECDSA curves. All curves are accepted
+	// if it is empty.
+	ECDSACurves []elliptic.Curve
+}
+
+// DefaultStrengthPolicy rejects RSA keys under 3072 bits and ECDSA keys on
+// curves other than the NIST curves P-256, P-384 and P-521.
+var DefaultStrengthPolicy = StrengthPolicy{
+	MinRSABits:  3072,
+	ECDSACurves: []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()},
+}
+
+// CheckKeyStrength returns ErrWeakKey if key does not meet policy, e.g. right
+// after loading a signing key. ED25519 keys, including the raw keys returned
+// by the loaders, always pass. Other key types are rejected.
+func CheckKeyStrength(key crypto.PrivateKey, policy StrengthPolicy) error {
+	switch k := typedPrivateKey(key).(type) {
+	case ed25519.PrivateKey:
+		return nil
+	case *rsa.PrivateKey:
+		if bits := k.N.BitLen(); bits < policy.MinRSABits {
+			return fmt.Errorf("%w: %d bit RSA key, want at least %d bits", ErrWeakKey, bits, policy.MinRSABits)
+		}
+		return nil
+	case *ecdsa.PrivateKey:
+		if len(policy.ECDSACurves) == 0 {
+			return nil
+		}
+		for _, curve := range policy.ECDSACurves {
+			if k.Curve == curve {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: ECDSA curve %s is not approved", ErrWeakKey, k.Curve.Params().Name)
+	default:
+		return fmt.Errorf("unsupported private key type %T", key)
+	}
+}
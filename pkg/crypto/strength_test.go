@@ -0,0 +1,58 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestCheckKeyStrength(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		key    crypto.PrivateKey
+		policy StrengthPolicy
+		want   error
+	}{
+		{"ED25519", edKey, DefaultStrengthPolicy, nil},
+		{"raw ED25519", []byte(edKey), DefaultStrengthPolicy, nil},
+		{"RSA 2048 by default", rsaKey, DefaultStrengthPolicy, ErrWeakKey},
+		{"RSA 2048 with 2048 minimum", rsaKey, StrengthPolicy{MinRSABits: 2048}, nil},
+		{"P-256", p256, DefaultStrengthPolicy, nil},
+		{"P-224 by default", p224, DefaultStrengthPolicy, ErrWeakKey},
+		{"P-224 without curve list", p224, StrengthPolicy{}, nil},
+	} {
+		if err := CheckKeyStrength(tt.key, tt.policy); !errors.Is(err, tt.want) {
+			t.Errorf(`CheckKeyStrength(%s) = %v, want %v`, tt.name, err, tt.want)
+		}
+	}
+	if err := CheckKeyStrength("not a key", StrengthPolicy{}); err == nil {
+		t.Errorf(`CheckKeyStrength("not a key") = nil, want error`)
+	}
+}
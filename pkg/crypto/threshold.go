@@ -0,0 +1,62 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+)
+
+// ErrThresholdNotMet is returned by VerifyThreshold if fewer than threshold
+// keys signed the data.
+var ErrThresholdNotMet = errors.New("signature threshold not met")
+
+// VerifyThreshold returns nil if at least threshold distinct keys of pubs
+// made valid signatures in sigs over data, see verifyMessage for the
+// supported algorithms. Keys are told apart by their NormalizedKeyID, so
+// listing a key twice does not count it twice, and each signature counts for
+// at most one key. Signatures not matching any key are ignored.
+func VerifyThreshold(pubs []crypto.PublicKey, data []byte, sigs [][]byte, threshold int) error {
+	if threshold < 1 {
+		return fmt.Errorf("invalid signature threshold %d", threshold)
+	}
+
+	var keys []crypto.PublicKey
+	seen := make(map[string]bool)
+	for _, pub := range pubs {
+		pub = typedPublicKey(pub)
+		id, err := NormalizedKeyID(pub)
+		if err != nil {
+			return err
+		}
+		if !seen[id] {
+			seen[id] = true
+			keys = append(keys, pub)
+		}
+	}
+	if threshold > len(keys) {
+		return fmt.Errorf("signature threshold %d exceeds the %d distinct keys", threshold, len(keys))
+	}
+
+	signed := make([]bool, len(keys))
+	valid := 0
+	for _, sig := range sigs {
+		for i, pub := range keys {
+			if signed[i] {
+				continue
+			}
+			if ok, err := verifyMessage(pub, data, sig); err == nil && ok {
+				signed[i] = true
+				valid++
+				break
+			}
+		}
+	}
+	if valid < threshold {
+		return fmt.Errorf("%w: %d of %d required signatures", ErrThresholdNotMet, valid, threshold)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestVerifyThreshold(t *testing.T) {
+	data := []byte("boot artifact")
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edSig := ed25519.Sign(edPriv, data)
+	ecdsaSig, err := SignReaderEncoded(ecdsaKey, bytes.NewReader(data), crypto.SHA256, EncodingRaw)
+	if err != nil {
+		t.Fatalf(`SignReaderEncoded() = _, %v, want nil`, err)
+	}
+	pubs := []crypto.PublicKey{edPub, &ecdsaKey.PublicKey, &otherKey.PublicKey}
+
+	for _, tt := range []struct {
+		name      string
+		pubs      []crypto.PublicKey
+		sigs      [][]byte
+		threshold int
+		wantErr   error
+	}{
+		{name: "2 of 3", pubs: pubs, sigs: [][]byte{ecdsaSig, edSig}, threshold: 2},
+		{name: "1 of 3", pubs: pubs, sigs: [][]byte{edSig}, threshold: 1},
+		{name: "not met", pubs: pubs, sigs: [][]byte{edSig}, threshold: 2, wantErr: ErrThresholdNotMet},
+		{name: "repeated signature", pubs: pubs, sigs: [][]byte{edSig, edSig}, threshold: 2, wantErr: ErrThresholdNotMet},
+		{name: "repeated key", pubs: []crypto.PublicKey{edPub, []byte(edPub), &otherKey.PublicKey}, sigs: [][]byte{edSig, edSig}, threshold: 2, wantErr: ErrThresholdNotMet},
+		{name: "garbage signature", pubs: pubs, sigs: [][]byte{[]byte("garbage"), edSig}, threshold: 2, wantErr: ErrThresholdNotMet},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyThreshold(tt.pubs, data, tt.sigs, tt.threshold); !errors.Is(err, tt.wantErr) {
+				t.Errorf(`VerifyThreshold() = %v, want %v`, err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := VerifyThreshold(pubs, data, [][]byte{edSig}, 0); err == nil {
+		t.Errorf(`VerifyThreshold(threshold 0) = nil, want error`)
+	}
+	if err := VerifyThreshold(pubs, data, [][]byte{edSig}, 4); err == nil {
+		t.Errorf(`VerifyThreshold(threshold 4) = nil, want error`)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxTimestampSkew is how far in the future a signing time passed to
+// VerifyWithTimestamp may lie, to allow for clocks that are slightly off.
+var MaxTimestampSkew = time.Minute
+
+// ErrSignatureExpired is returned by VerifyWithTimestamp if the signature was
+// made outside of its validity window.
+var ErrSignatureExpired = errors.New("signature is outside its validity window")
+
+// VerifyWithTimestamp verifies sig over data with pub like verifyMessage, and
+// additionally rejects it with ErrSignatureExpired if signedAt is more than
+// maxAge in the past or more than MaxTimestampSkew in the future. signedAt
+// has to come from a trusted source, like an RFC 3161 token or a field of a
+// verified attestation, as the signature itself does not cover it.
+func VerifyWithTimestamp(pub crypto.PublicKey, data, sig []byte, signedAt time.Time, maxAge time.Duration) (bool, error) {
+	if maxAge <= 0 {
+		return false, fmt.Errorf("invalid maximum signature age %v", maxAge)
+	}
+	now := time.Now()
+	if age := now.Sub(signedAt); age > maxAge || age < -MaxTimestampSkew {
+		return false, fmt.Errorf("%w: signed at %v, %v ago, maximum age %v", ErrSignatureExpired, signedAt, age, maxAge)
+	}
+	return verifyMessage(typedPublicKey(pub), data, sig)
+}
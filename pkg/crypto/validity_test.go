@@ -0,0 +1,47 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestVerifyWithTimestamp(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("kernel")
+	sig := ed25519.Sign(priv, data)
+	now := time.Now()
+
+	for _, tt := range []struct {
+		name     string
+		sig      []byte
+		signedAt time.Time
+		wantOK   bool
+		wantErr  error
+	}{
+		{name: "fresh", sig: sig, signedAt: now.Add(-time.Minute), wantOK: true},
+		{name: "slightly in the future", sig: sig, signedAt: now.Add(10 * time.Second), wantOK: true},
+		{name: "too old", sig: sig, signedAt: now.Add(-2 * time.Hour), wantErr: ErrSignatureExpired},
+		{name: "far in the future", sig: sig, signedAt: now.Add(time.Hour), wantErr: ErrSignatureExpired},
+		{name: "bad signature", sig: make([]byte, ed25519.SignatureSize), signedAt: now},
+	} {
+		ok, err := VerifyWithTimestamp(pub, data, tt.sig, tt.signedAt, time.Hour)
+		if ok != tt.wantOK || !errors.Is(err, tt.wantErr) {
+			t.Errorf(`VerifyWithTimestamp(%s) = %t, %v, want %t, %v`, tt.name, ok, err, tt.wantOK, tt.wantErr)
+		}
+	}
+
+	if _, err := VerifyWithTimestamp(pub, data, sig, now, 0); err == nil {
+		t.Errorf(`VerifyWithTimestamp(maxAge 0) = _, nil, want error`)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// curve25519P is the field prime 2^255 - 19 shared by Ed25519 and X25519.
+var curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// Ed25519ToX25519 converts an ED25519 private key into the X25519 private
+// key of the same identity, so that a signing key can also be used for key
+// agreement (RFC 7748). It is the clamped first half of the SHA-512 hash of
+// the seed, just like the scalar Ed25519 signs with.
+func Ed25519ToX25519(priv ed25519.PrivateKey) ([]byte, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("ED25519 private key has %d bytes, want %d", len(priv), ed25519.PrivateKeySize)
+	}
+	h := sha512.Sum512(priv.Seed())
+	h[0] &= 248
+	h[31] &= 127
+	h[31] |= 64
+	return h[:32], nil
+}
+
+// Ed25519PublicToX25519 converts an ED25519 public key into the X25519
+// public key matching Ed25519ToX25519, using the birational map
+// u = (1 + y) / (1 - y) from the Edwards to the Montgomery curve.
+func Ed25519PublicToX25519(pub ed25519.PublicKey) ([]byte, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("ED25519 public key has %d bytes, want %d", len(pub), ed25519.PublicKeySize)
+	}
+	// The key is y in little endian, with the sign of x in the top bit.
+	le := make([]byte, ed25519.PublicKeySize)
+	copy(le, pub)
+	le[31] &= 0x7f
+	y := new(big.Int).SetBytes(reverse(le))
+	if y.Cmp(curve25519P) >= 0 {
+		return nil, errors.New("ED25519 public key is not canonically encoded")
+	}
+
+	num := new(big.Int).Add(big.NewInt(1), y)
+	den := new(big.Int).Sub(big.NewInt(1), y)
+	den.Mod(den, curve25519P)
+	if den.Sign() == 0 {
+		return nil, errors.New("ED25519 public key is the identity point")
+	}
+	u := num.Mul(num, den.ModInverse(den, curve25519P))
+	u.Mod(u, curve25519P)
+
+	out := make([]byte, 32)
+	u.FillBytes(out)
+	return reverse(out), nil
+}
+
+// reverse reverses b in place, converting between big and little endian.
+func reverse(b []byte) []byte {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
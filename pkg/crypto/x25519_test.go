@@ -0,0 +1,51 @@
+// Copyright 2023 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestEd25519ToX25519(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xPriv, err := Ed25519ToX25519(priv)
+		if err != nil {
+			t.Fatalf(`Ed25519ToX25519(priv) = _, %v, want nil`, err)
+		}
+		xPub, err := Ed25519PublicToX25519(pub)
+		if err != nil {
+			t.Fatalf(`Ed25519PublicToX25519(pub) = _, %v, want nil`, err)
+		}
+		want, err := curve25519.X25519(xPriv, curve25519.Basepoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(xPub, want) {
+			t.Errorf(`Ed25519PublicToX25519(%x) = %x, want %x`, []byte(pub), xPub, want)
+		}
+	}
+
+	if _, err := Ed25519ToX25519(make([]byte, 10)); err == nil {
+		t.Errorf(`Ed25519ToX25519(10 bytes) = _, nil, want error`)
+	}
+	// y = 1 is the identity point, which has no Montgomery counterpart.
+	identity := make([]byte, ed25519.PublicKeySize)
+	identity[0] = 1
+	if _, err := Ed25519PublicToX25519(identity); err == nil {
+		t.Errorf(`Ed25519PublicToX25519(identity) = _, nil, want error`)
+	}
+	if _, err := Ed25519PublicToX25519(bytes.Repeat([]byte{0xff}, ed25519.PublicKeySize)); err == nil {
+		t.Errorf(`Ed25519PublicToX25519(non canonical) = _, nil, want error`)
+	}
+}